@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"strings"
+)
+
+// Caller identifies who made a request, once they've been authenticated, which tenant's data they're working with, and their role there. Scopes limits what they can do when they used an access token instead of logging in; it's nil when they logged in. RequestID ties what's done on their behalf to the request in the logs, and Locale is the language they'd like messages in.
+type Caller struct {
+	UserID    string
+	TenantID  string
+	Role      string
+	Scopes    []string
+	RequestID string
+	Locale    string
+}
+
+// Logger returns a logger that tags each line with the caller's request and user.
+func (c Caller) Logger() Logger {
+	logger := requestLogger(c.RequestID)
+	if c.UserID != "" {
+		logger = logger.With("userId", c.UserID)
+	}
+	return logger
+}
+
+// HasScope says whether the caller may use this scope, like "plans:read". Someone who logged in may use any scope, and a write scope includes reading the same resource.
+func (c Caller) HasScope(scope string) bool {
+	if c.Scopes == nil {
+		return true
+	}
+	for _, granted := range c.Scopes {
+		if granted == scope || (strings.HasSuffix(scope, ":read") && granted == strings.TrimSuffix(scope, ":read")+":write") {
+			return true
+		}
+	}
+	return false
+}
+
+type callerKey struct{}
+
+// WithCaller returns a copy of the context that carries the caller.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// GetCaller returns the caller the context carries, if it has one.
+func GetCaller(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallerHasScope(t *testing.T) {
+	for _, testCase := range []struct {
+		scopes   []string
+		scope    string
+		expected bool
+	}{
+		{nil, "plans:write", true},
+		{[]string{}, "plans:read", false},
+		{[]string{"plans:read"}, "plans:read", true},
+		{[]string{"plans:read"}, "plans:write", false},
+		{[]string{"plans:write"}, "plans:read", true},
+		{[]string{"plans:write"}, "budgets:read", false},
+		{[]string{"budgets:read", "plans:write"}, "budgets:read", true},
+	} {
+		assert.Equal(t, testCase.expected, Caller{Scopes: testCase.scopes}.HasScope(testCase.scope), "CASE: %v has %s", testCase.scopes, testCase.scope)
+	}
+}
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"encoding"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fields tagged `readonly:"true"` are set by the server, so clients may not provide them.
+const readOnlyTag = "readonly"
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var dateType = reflect.TypeOf(Date{})
+
+// DecodeStrict reads a JSON request body into result, which must be a pointer. Unlike a plain json.Decoder, it reports unknown fields, read-only fields, and values of the wrong type as a ValidationError naming each offending field.
+func DecodeStrict(body io.Reader, result interface{}) error {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return ParseErr
+	}
+	err = CheckStrict(data, result)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		if ute, ok := err.(*json.UnmarshalTypeError); ok {
+			return NewValidationError(ute.Field, WrongTypeCode, "", Arg("expected", ute.Type), Arg("actual", ute.Value))
+		}
+		return ParseErr
+	}
+	return nil
+}
+
+// CheckStrict makes sure the JSON data could be decoded into the model without any unknown, read-only, or mistyped fields. Nulls are always allowed, which suits merge patches, where null means "remove this".
+func CheckStrict(data []byte, model interface{}) error {
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return ParseErr
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	checker := &strictChecker{}
+	checker.check(value, t, "")
+	return CombineErrors(checker.errs...)
+}
+
+// strictChecker walks a generic JSON value alongside the Go type it's meant for, collecting an error for each field that doesn't fit.
+type strictChecker struct {
+	errs []error
+}
+
+func (c *strictChecker) fail(path, code, variant string) {
+	c.errs = append(c.errs, NewValidationError(path, code, variant))
+}
+
+func (c *strictChecker) check(value interface{}, t reflect.Type, path string) {
+	if value == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types that decode themselves are only checked as far as we can tell from the outside.
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		text, ok := value.(string)
+		if !ok {
+			c.fail(path, WrongTypeCode, "text")
+		} else if t == dateType {
+			if !DatePattern.MatchString(text) {
+				c.fail(path, BadDateCode, "")
+			}
+		} else if err := reflect.New(t).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			c.fail(path, WrongTypeCode, "format")
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.fail(path, WrongTypeCode, "object")
+			return
+		}
+		fields := jsonFields(t)
+		for _, name := range sortedKeys(object) {
+			fieldValue := object[name]
+			fieldPath := joinPath(path, name)
+			field, ok := fields[name]
+			if !ok {
+				c.fail(fieldPath, UnknownFieldCode, "")
+			} else if field.Tag.Get(readOnlyTag) == "true" {
+				c.fail(fieldPath, ReadOnlyFieldCode, "")
+			} else {
+				c.check(fieldValue, field.Type, fieldPath)
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.fail(path, WrongTypeCode, "object")
+			return
+		}
+		for _, name := range sortedKeys(object) {
+			c.check(object[name], t.Elem(), joinPath(path, name))
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			c.fail(path, WrongTypeCode, "list")
+			return
+		}
+		for idx, itemValue := range items {
+			c.check(itemValue, t.Elem(), joinPath(path, strconv.Itoa(idx)))
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			c.fail(path, WrongTypeCode, "text")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			c.fail(path, WrongTypeCode, "boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := value.(json.Number)
+		if !ok {
+			c.fail(path, WrongTypeCode, "integer")
+		} else if _, err := strconv.ParseInt(number.String(), 10, t.Bits()); err != nil {
+			c.fail(path, WrongTypeCode, "integer")
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(json.Number)
+		if !ok {
+			c.fail(path, WrongTypeCode, "integer")
+		} else if _, err := strconv.ParseUint(number.String(), 10, t.Bits()); err != nil {
+			c.fail(path, WrongTypeCode, "unsigned")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			c.fail(path, WrongTypeCode, "number")
+		}
+	}
+}
+
+// jsonFields maps each JSON field name to the struct field encoding/json would decode it into, flattening untagged embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				for embeddedName, embeddedField := range jsonFields(embeddedType) {
+					// Fields on the outer struct win over embedded ones.
+					if _, exists := fields[embeddedName]; !exists {
+						fields[embeddedName] = embeddedField
+					}
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// Lets us report problems in a predictable order.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type strictAmount struct {
+	Cents int `json:"amount"`
+}
+
+type strictItem struct {
+	Name string `json:"name"`
+	strictAmount
+}
+
+type strictDoc struct {
+	ID      string       `json:"id" readonly:"true"`
+	Name    string       `json:"name"`
+	Active  bool         `json:"active"`
+	Start   *Date        `json:"start,omitempty"`
+	Items   []strictItem `json:"items"`
+	Ignored string       `json:"-"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	result := strictDoc{}
+	err := DecodeStrict(strings.NewReader(`{"name":"x","active":true,"start":"2018-05-19","items":[{"name":"rent","amount":100}]}`), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, strictDoc{
+		Name:   "x",
+		Active: true,
+		Start:  &Date{Year: 2018, Month: 5, Day: 19},
+		Items:  []strictItem{{Name: "rent", strictAmount: strictAmount{Cents: 100}}},
+	}, result)
+}
+
+func TestDecodeStrictNulls(t *testing.T) {
+	result := strictDoc{}
+	err := DecodeStrict(strings.NewReader(`{"name":null,"start":null,"items":null}`), &result)
+	assert.Nil(t, err)
+}
+
+func TestDecodeStrictErrors(t *testing.T) {
+	result := strictDoc{}
+	err := DecodeStrict(strings.NewReader(`{
+		"id": "abc",
+		"nmae": "typo",
+		"active": "yes",
+		"start": "May 19",
+		"Ignored": "x",
+		"items": [
+			{"name": "rent", "amount": 1.5},
+			{"name": 3, "amont": 1}
+		]
+	}`), &result)
+	assert.Equal(t, &ValidationError{
+		BasicError: *invalidDataErr,
+		Fields: []InvalidField{
+			{FieldName: "Ignored", Code: UnknownFieldCode, Message: "This isn't a field we recognize."},
+			{FieldName: "active", Code: WrongTypeCode, Message: "Expected true or false.", variant: "boolean"},
+			{FieldName: "id", Code: ReadOnlyFieldCode, Message: "This field is set by the server, so you can't provide it."},
+			{FieldName: "items.0.amount", Code: WrongTypeCode, Message: "Expected a whole number.", variant: "integer"},
+			{FieldName: "items.1.amont", Code: UnknownFieldCode, Message: "This isn't a field we recognize."},
+			{FieldName: "items.1.name", Code: WrongTypeCode, Message: "Expected text.", variant: "text"},
+			{FieldName: "nmae", Code: UnknownFieldCode, Message: "This isn't a field we recognize."},
+			{FieldName: "start", Code: BadDateCode, Message: "Date must be in the format YYYY-MM-DD, and it must be a valid date."},
+		},
+	}, err)
+}
+
+func TestDecodeStrictUnparseable(t *testing.T) {
+	result := strictDoc{}
+	err := DecodeStrict(strings.NewReader(`{"name":`), &result)
+	assert.Equal(t, ParseErr, err)
+
+	err = DecodeStrict(strings.NewReader(`[]`), &result)
+	assert.Equal(t, NewValidationError("", WrongTypeCode, "object"), err)
+}
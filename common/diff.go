@@ -0,0 +1,101 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange describes one field that differs between two versions of a document. Its path uses the same dotted names as validation errors, so line items are named by their ID (e.g. "bills.<id>.amount").
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Diff compares two JSON documents field by field. Nested objects are compared field by field too, as are lists of objects with IDs (like line items), which are matched up by ID. Any other list is compared as a whole. An empty document counts as an empty object, so a new document's fields all show up as added.
+func Diff(old, new []byte) ([]FieldChange, error) {
+	oldValue, err := decodeDiffValue(old)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := decodeDiffValue(new)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]FieldChange, 0)
+	diffValues("", oldValue, newValue, &changes)
+	return changes, nil
+}
+
+// Unlike decodeJSONValue, numbers become float64s, so the changes can be stored and compared as plain numbers.
+func decodeDiffValue(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, ParseErr
+	}
+	return value, nil
+}
+
+func diffValues(path string, old, new interface{}, changes *[]FieldChange) {
+	oldObject, oldIsObject := old.(map[string]interface{})
+	newObject, newIsObject := new.(map[string]interface{})
+	if oldIsObject && newIsObject {
+		keys := sortedKeys(oldObject)
+		for _, key := range sortedKeys(newObject) {
+			if _, ok := oldObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			diffValues(joinPath(path, key), oldObject[key], newObject[key], changes)
+		}
+		return
+	}
+
+	oldItems, oldOK := itemsByID(old)
+	newItems, newOK := itemsByID(new)
+	if oldOK && newOK {
+		ids := sortedKeys(oldItems)
+		for _, id := range sortedKeys(newItems) {
+			if _, ok := oldItems[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			diffValues(joinPath(path, id), oldItems[id], newItems[id], changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, FieldChange{Path: path, Old: old, New: new})
+	}
+}
+
+// If the value is a list where every item is an object with an ID, this maps each ID to its item.
+func itemsByID(value interface{}) (map[string]interface{}, bool) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	items := map[string]interface{}{}
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, ok := object["id"].(string)
+		if !ok || len(id) == 0 {
+			return nil, false
+		}
+		items[id] = object
+	}
+	return items, true
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	for _, testCase := range []struct {
+		desc     string
+		old      string
+		new      string
+		expected []FieldChange
+	}{
+		{"same", `{"a":1}`, `{"a":1}`, []FieldChange{}},
+		{"changed field", `{"a":1,"b":"x"}`, `{"a":2,"b":"x"}`, []FieldChange{{"a", float64(1), float64(2)}}},
+		{"added and removed", `{"a":1}`, `{"b":2}`, []FieldChange{{"a", float64(1), nil}, {"b", nil, float64(2)}}},
+		{"nested", `{"a":{"b":1,"c":1}}`, `{"a":{"b":2,"c":1}}`, []FieldChange{{"a.b", float64(1), float64(2)}}},
+		{"plain list", `{"a":[1,2]}`, `{"a":[2,1]}`, []FieldChange{{"a", []interface{}{float64(1), float64(2)}, []interface{}{float64(2), float64(1)}}}},
+		{
+			"line items by ID",
+			`{"bills":[{"id":"x","amount":1},{"id":"y","amount":1}]}`,
+			`{"bills":[{"id":"y","amount":1},{"id":"x","amount":5},{"id":"z","amount":3}]}`,
+			[]FieldChange{
+				{"bills.x.amount", float64(1), float64(5)},
+				{"bills.z", nil, map[string]interface{}{"id": "z", "amount": float64(3)}},
+			},
+		},
+		{"new document", ``, `{"a":1}`, []FieldChange{{"a", nil, float64(1)}}},
+	} {
+		actual, err := Diff([]byte(testCase.old), []byte(testCase.new))
+		assert.Nil(t, err, "CASE: %s", testCase.desc)
+		assert.Equal(t, testCase.expected, actual, "CASE: %s", testCase.desc)
+	}
+}
+
+func TestDiffInvalid(t *testing.T) {
+	_, err := Diff([]byte(`{}`), []byte(`{`))
+	assert.Equal(t, ParseErr, err)
+}
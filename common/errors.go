@@ -2,20 +2,78 @@ package common
 
 import (
 	"fmt"
-	"log"
 	"strings"
 )
 
 // ParseErr is a reusable error for any time we can't parse a request body, or perhaps the path vars, query params, or header values too.
-var ParseErr = &BasicError{
-	Code:    "CANNOT_PARSE",
-	Message: "Couldn't parse the data you provided.",
-}
+var ParseErr = NewError(400, "CANNOT_PARSE", "Couldn't parse the data you provided.")
+
+// UnauthorizedErr is a reusable error for any time a request doesn't come with valid credentials.
+var UnauthorizedErr = NewError(401, "UNAUTHORIZED", "You must log in first. Provide a valid token in the Authorization header, like: Bearer <token>")
+
+// BadCredentialsErr is a reusable error for any time someone tries to log in with the wrong email or password.
+var BadCredentialsErr = NewError(401, "BAD_CREDENTIALS", "That email and password don't match any account.")
+
+// ForbiddenErr is a reusable error for any time the caller is logged in, but their role doesn't allow what they asked for.
+var ForbiddenErr = NewError(403, "FORBIDDEN", "Your role in this household doesn't allow that.")
+
+// InsufficientScopeErr is a reusable error for any time an access token is used for something its scopes don't cover.
+var InsufficientScopeErr = NewError(403, "INSUFFICIENT_SCOPE", "This access token's scopes don't allow that. Use a token with the right scopes, or log in.")
+
+// MissingTenantErr is a reusable error for any time the server is configured to take the tenant from a request header, and the request doesn't have it.
+var MissingTenantErr = NewError(400, "MISSING_TENANT", "Say which tenant's data you're working with in the tenant header.")
 
 // NotFoundErr is a reusable error for any time when we can't find something.
-var NotFoundErr = &BasicError{
-	Code:    "NOT_FOUND",
-	Message: "One or more resources you referenced couldn't be found.",
+var NotFoundErr = NewError(404, "NOT_FOUND", "One or more resources you referenced couldn't be found.")
+
+// IdempotencyKeyReusedErr is a reusable error for any time an Idempotency-Key is sent again with a different request.
+var IdempotencyKeyReusedErr = NewError(422, "IDEMPOTENCY_KEY_REUSED", "This Idempotency-Key was already used for a different request. Use a new key for each distinct request.")
+
+// IdempotencyKeyInProgressErr is a reusable error for any time a retry arrives while the original request is still being handled.
+var IdempotencyKeyInProgressErr = NewError(409, "IDEMPOTENCY_KEY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed. Try again shortly.")
+
+// InUseErr is a reusable error for any time something can't be deleted because other resources still refer to it.
+var InUseErr = NewError(409, "IN_USE", "This is still referred to elsewhere, so it can't be deleted. Reassign or remove those references first.")
+
+// LastOwnerErr is a reusable error for any time a change would leave a household without an owner.
+var LastOwnerErr = NewError(409, "LAST_OWNER", "A household must always have an owner. Make someone else an owner first.")
+
+// RateLimitedErr is a reusable error for any time a client has made too many requests too quickly.
+var RateLimitedErr = NewError(429, "RATE_LIMITED", "You've made too many requests. Wait a moment, then try again.")
+
+// BatchAbortedErr is a reusable error for batch operations that were skipped because an earlier one failed.
+var BatchAbortedErr = NewError(424, "BATCH_ABORTED", "This operation wasn't attempted because an earlier operation in the batch failed.")
+
+// InternalErr is a reusable error for any time something goes wrong that the client couldn't have prevented, like a bug.
+var InternalErr = NewError(500, "INTERNAL_ERROR", "Sorry, something went wrong on our end. Try again later!")
+
+// UnavailableErr is a reusable error for any time we can't reach the database.
+var UnavailableErr = NewError(503, "UNAVAILABLE", "We can't handle requests right now. Try again shortly.")
+
+// MethodNotAllowedErr is a reusable error for any time a path exists, but doesn't support the request's method.
+var MethodNotAllowedErr = NewError(405, "METHOD_NOT_ALLOWED", "This path doesn't support that method.")
+
+// UnsupportedMediaTypeErr is a reusable error for any time a request body's Content-Type isn't one the route understands.
+var UnsupportedMediaTypeErr = NewError(415, "UNSUPPORTED_MEDIA_TYPE", "This route doesn't understand that Content-Type. Send a JSON merge patch, as application/merge-patch+json.")
+
+// errorStatuses maps each error code to the status code it's reported with. Codes get here by being defined with NewError.
+var errorStatuses = map[string]int{}
+
+// NewError defines a reusable error, registering the status code it's reported with. Define errors at package level, so every code is registered before any request is handled.
+func NewError(status int, code, message string) *BasicError {
+	if existing, ok := errorStatuses[code]; ok && existing != status {
+		panic(fmt.Sprintf("error code %s is already registered with status %d", code, existing))
+	}
+	errorStatuses[code] = status
+	return &BasicError{Code: code, Message: message}
+}
+
+// StatusFor returns the status code registered for an error code, or 500 for a code we don't know.
+func StatusFor(code string) int {
+	if status, ok := errorStatuses[code]; ok {
+		return status
+	}
+	return 500
 }
 
 // BasicError is our custom format for passing helpful information around. The main reason for this is so our responses can guess the appropriate status code and also provide helpful info to the client.
@@ -40,15 +98,9 @@ func (e BasicError) Error() string {
 	return e.Message
 }
 
-// Lets each type be smart
+// ResponseCode returns the status code registered for the error's code.
 func (e BasicError) ResponseCode() int {
-	if e.Code == ParseErr.Code {
-		return 400
-	} else if e.Code == NotFoundErr.Code {
-		return 404
-	} else {
-		return 500
-	}
+	return StatusFor(e.Code)
 }
 
 // ValidationError extends BasicError to include an array of fields that failed validation.
@@ -82,6 +134,28 @@ type InvalidField struct {
 	FieldName string `json:"fieldName"`
 	Code      string `json:"code"`
 	Message   string `json:"message"`
+	variant   string
+	args      []MessageArg
+}
+
+// Localized returns a copy of the field with its message in the locale.
+func (f InvalidField) Localized(locale string) InvalidField {
+	if message, ok := Message(locale, f.Code, f.variant, f.args...); ok {
+		f.Message = message
+	}
+	return f
+}
+
+// LocalizeFields returns a copy of the fields with their messages in the locale.
+func LocalizeFields(fields []InvalidField, locale string) []InvalidField {
+	if fields == nil {
+		return nil
+	}
+	localized := make([]InvalidField, len(fields))
+	for idx, field := range fields {
+		localized[idx] = field.Localized(locale)
+	}
+	return localized
 }
 
 // These are codes for errors on fields (`InvalidField`).
@@ -93,25 +167,50 @@ const (
 	BadUUIDFormatCode  string = "BAD_UUID_FORMAT"
 	NonexistentRefCode string = "NONEXISTENT_REF"
 	NumOutOfRangeCode  string = "NUM_OUT_OF_RANGE"
+	DuplicateCode      string = "DUPLICATE"
+	BadETagCode        string = "BAD_ETAG_FORMAT"
+	UnknownFieldCode   string = "UNKNOWN_FIELD"
+	ReadOnlyFieldCode  string = "READ_ONLY_FIELD"
+	WrongTypeCode      string = "WRONG_TYPE"
+	CycleCode          string = "CYCLE"
+	TooShortCode       string = "TOO_SHORT"
+	BadEmailCode       string = "BAD_EMAIL_FORMAT"
+	RequiredTextCode   string = "REQUIRED_TEXT"
+)
+
+// These are codes for warnings on fields, which are reported like field errors but don't stop anything from being saved.
+const (
+	NegativeBalanceCode    string = "NEGATIVE_BALANCE"
+	ExceedsIncomeCode      string = "EXCEEDS_INCOME"
+	DayNotInEveryMonthCode string = "DAY_NOT_IN_EVERY_MONTH"
 )
 
-const invalidDataCode = "INVALID_DATA"
-const invalidDataMessage = "One or more fields was either missing or invalid."
+var invalidDataErr = NewError(422, "INVALID_DATA", "One or more fields was either missing or invalid.")
 
-// NewValidationError creates a new ValidationError with code INVALID_DATA and instantiates a single field error.
-func NewValidationError(fieldName, code, message string, args ...interface{}) *ValidationError {
+// NewValidationError creates a new ValidationError with code INVALID_DATA and instantiates a single field error. Its message comes from the catalogs, by code and variant (which may be empty), with args filling in the message's placeholders. It's in English until the field is localized.
+func NewValidationError(fieldName, code, variant string, args ...MessageArg) *ValidationError {
 	return &ValidationError{
-		BasicError: BasicError{
-			Code:    invalidDataCode,
-			Message: invalidDataMessage,
-		},
-		Fields: []InvalidField{
-			{
-				FieldName: fieldName,
-				Code:      code,
-				Message:   fmt.Sprintf(message, args...),
-			},
-		},
+		BasicError: *invalidDataErr,
+		Fields:     []InvalidField{newInvalidField(fieldName, code, variant, args)},
+	}
+}
+
+// NewWarning describes something about a field that's allowed, but probably isn't what the client meant, like spending more than they earn. It looks just like a field's validation error, but doesn't stop anything from being saved.
+func NewWarning(fieldName, code, variant string, args ...MessageArg) InvalidField {
+	return newInvalidField(fieldName, code, variant, args)
+}
+
+func newInvalidField(fieldName, code, variant string, args []MessageArg) InvalidField {
+	message, ok := Message(DefaultLocale, code, variant, args...)
+	if !ok {
+		message = code
+	}
+	return InvalidField{
+		FieldName: fieldName,
+		Code:      code,
+		Message:   message,
+		variant:   variant,
+		args:      args,
 	}
 }
 
@@ -122,7 +221,7 @@ func AddValidationContext(err error, fieldName string) error {
 	}
 	validationErr, ok := err.(*ValidationError)
 	if !ok {
-		log.Printf("One of the errors passed to PrefixFieldNames was not a ValidationError like expected! %#v", err)
+		Log.Error("One of the errors passed to PrefixFieldNames was not a ValidationError like expected! %#v", err)
 		return err
 	}
 	for idx, field := range validationErr.Fields {
@@ -137,6 +236,20 @@ func AddValidationContext(err error, fieldName string) error {
 	return validationErr
 }
 
+// AddWarningContext prefixes the warnings' field names with some parent field, the same way AddValidationContext does for errors.
+func AddWarningContext(warnings []InvalidField, fieldName string) []InvalidField {
+	result := make([]InvalidField, 0, len(warnings))
+	for _, warning := range warnings {
+		if len(warning.FieldName) > 0 {
+			warning.FieldName = fieldName + "." + warning.FieldName
+		} else {
+			warning.FieldName = fieldName
+		}
+		result = append(result, warning)
+	}
+	return result
+}
+
 // CombineErrors takes several errors and returns a single validation error.
 func CombineErrors(errs ...error) error {
 	fields := make([]InvalidField, 0, 5)
@@ -146,7 +259,7 @@ func CombineErrors(errs ...error) error {
 		}
 		validationErr, ok := err.(*ValidationError)
 		if !ok {
-			log.Printf("One of the errors passed to CombineErrors was not a ValidationError like expected! %#v", err)
+			Log.Error("One of the errors passed to CombineErrors was not a ValidationError like expected! %#v", err)
 			return err
 		}
 		fields = append(fields, validationErr.Fields...)
@@ -155,10 +268,7 @@ func CombineErrors(errs ...error) error {
 		return nil
 	}
 	return &ValidationError{
-		BasicError: BasicError{
-			Code:    invalidDataCode,
-			Message: invalidDataMessage,
-		},
-		Fields: fields,
+		BasicError: *invalidDataErr,
+		Fields:     fields,
 	}
 }
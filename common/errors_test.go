@@ -3,7 +3,7 @@ package common
 import "testing"
 
 func TestAddValidationContext(t *testing.T) {
-	inputErr := NewValidationError("foo", "MISSING", "lakjsdf")
+	inputErr := NewValidationError("foo", MissingCode, "")
 	actualErr := AddValidationContext(inputErr, "thisIsContext")
 	actualValidationErr, ok := actualErr.(*ValidationError)
 	if !ok {
@@ -22,8 +22,8 @@ func TestAddValidationContext(t *testing.T) {
 
 func TestCombineErrors(t *testing.T) {
 	actualErr := CombineErrors(
-		NewValidationError("one", "code1", "message1"),
-		NewValidationError("two", "code2", "message2"),
+		NewValidationError("one", MissingCode, "name"),
+		NewValidationError("two", TooShortCode, "password", Arg("min", 8)),
 	)
 	actualValidationErr, ok := actualErr.(*ValidationError)
 	if !ok {
@@ -37,20 +37,20 @@ func TestCombineErrors(t *testing.T) {
 	fieldsFound := map[string]bool{}
 	for _, field := range actualValidationErr.Fields {
 		if field.FieldName == "one" {
-			if field.Code != "code1" {
+			if field.Code != MissingCode {
 				t.Logf("Got wrong code for field 1: %s", field.Code)
 				t.Fail()
 			}
-			if field.Message != "message1" {
+			if field.Message != "You must provide a name." {
 				t.Logf("Got wrong message for field 1: %s", field.Message)
 				t.Fail()
 			}
 		} else if field.FieldName == "two" {
-			if field.Code != "code2" {
+			if field.Code != TooShortCode {
 				t.Logf("Got wrong code for field 2: %s", field.Code)
 				t.Fail()
 			}
-			if field.Message != "message2" {
+			if field.Message != "Your password must be at least 8 characters long." {
 				t.Logf("Got wrong message for field 2: %s", field.Message)
 				t.Fail()
 			}
@@ -2,43 +2,129 @@ package common
 
 import (
 	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hjkelly/zbbapi/metrics"
 )
 
-// WriteResponse preps some data to a response with the given status code.
+var validationErrors = metrics.NewCounter("zbbapi_validation_errors_total", "How many invalid fields we've reported to clients, by code.", "code")
+
+// tagged is implemented by anything with a version we can report in an ETag header.
+type tagged interface {
+	ETag() string
+}
+
+// Localizable is implemented by responses with messages of their own, like warnings, so they can be put in the client's locale before they're written.
+type Localizable interface {
+	Localize(locale string)
+}
+
+// WriteResponse preps some data to a response with the given status code. If the data can't be encoded, the client gets an internal error instead.
 func WriteResponse(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	if l, ok := data.(Localizable); ok {
+		l.Localize(responseLocale(w))
+	}
+	var body []byte
 	if data != nil {
-		err := json.NewEncoder(w).Encode(data)
+		var err error
+		body, err = json.Marshal(data)
 		if err != nil {
-			// TODO: Log error so we can avoid this in the future.
-			panic(err)
+			requestLogger(w.Header().Get(RequestIDHeader)).Error("Couldn't encode a %d response: %s", status, err.Error())
+			status = InternalErr.ResponseCode()
+			body, _ = json.Marshal(ErrorEnvelope{Code: InternalErr.Code, Message: InternalErr.Message, RequestID: w.Header().Get(RequestIDHeader)})
+			data = nil
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if t, ok := data.(tagged); ok {
+		w.Header().Set("ETag", t.ETag())
+	}
+	w.WriteHeader(status)
+	if body != nil {
+		w.Write(append(body, '\n'))
+	}
+}
+
+// WantsExpansion says whether the client asked for a reference to be embedded in the response, as in ?expand=category. Several can be listed, separated by commas or by repeating the parameter.
+func WantsExpansion(r *http.Request, name string) bool {
+	for _, value := range r.URL.Query()["expand"] {
+		for _, expansion := range strings.Split(value, ",") {
+			if strings.TrimSpace(expansion) == name {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// WantsDryRun says whether the client asked, with ?dryRun=true, to see what a change would do without saving it.
+func WantsDryRun(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("dryRun")
+	if value == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, NewValidationError("dryRun", WrongTypeCode, "boolean")
+	}
+	return dryRun, nil
+}
+
+// ErrorEnvelope is the shape of every error response: a code and message, the request's ID so the client can tell us which request went wrong, the fields that failed validation (if any), and the current representation of a stale resource (if that's the problem).
+type ErrorEnvelope struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	RequestID string         `json:"requestId,omitempty"`
+	Fields    []InvalidField `json:"fields,omitempty"`
+	Current   interface{}    `json:"current,omitempty"`
 }
 
-// WriteErrorResponse preps the response by trying to guess the type of the error.
+// WriteErrorResponse preps the response by trying to guess the type of the error. Unexpected errors are logged along with the request's ID, which the request ID middleware has already put in the response headers. Messages are in the locale the locale middleware put in the Content-Language header.
 func WriteErrorResponse(w http.ResponseWriter, err error) {
+	status, envelope := ErrorResponse(err, responseLocale(w))
+	envelope.RequestID = w.Header().Get(RequestIDHeader)
+	if status >= 500 {
+		requestLogger(envelope.RequestID).Error("Unexpected error: %s", err.Error())
+	}
+	if t, ok := envelope.Current.(tagged); ok {
+		w.Header().Set("ETag", t.ETag())
+	}
+	WriteResponse(w, status, envelope)
+}
+
+// Returns the locale the locale middleware put in the Content-Language header, or the default if it hasn't.
+func responseLocale(w http.ResponseWriter) string {
+	if locale := w.Header().Get(ContentLanguageHeader); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// ErrorResponse guesses the status code and envelope that best describe the error to a client, with messages in the locale. Errors we don't recognize are reported as internal errors, without their details.
+func ErrorResponse(err error, locale string) (int, ErrorEnvelope) {
 	switch err.(type) {
+	case *StaleError:
+		se := err.(*StaleError)
+		return se.ResponseCode(), ErrorEnvelope{Code: se.Code, Message: localizedMessage(locale, se.Code, se.Message), Current: se.Current}
 	case BasicError:
 		be := err.(BasicError)
-		WriteResponse(w, be.ResponseCode(), be)
+		return be.ResponseCode(), ErrorEnvelope{Code: be.Code, Message: localizedMessage(locale, be.Code, be.Message)}
 	case *BasicError:
 		be := err.(*BasicError)
-		WriteResponse(w, be.ResponseCode(), be)
+		return be.ResponseCode(), ErrorEnvelope{Code: be.Code, Message: localizedMessage(locale, be.Code, be.Message)}
 	case ValidationError, *ValidationError:
-		WriteResponse(w, 422, err)
+		ve, _ := GetValidationError(err)
+		for _, field := range ve.Fields {
+			validationErrors.Inc(field.Code)
+		}
+		return invalidDataErr.ResponseCode(), ErrorEnvelope{Code: ve.Code, Message: localizedMessage(locale, ve.Code, ve.Message), Fields: LocalizeFields(ve.Fields, locale)}
 	case *json.UnmarshalTypeError:
 		ute := err.(*json.UnmarshalTypeError)
-		WriteResponse(w, 422, map[string]string{
-			"message": fmt.Sprintf("Got value of wrong type for %s. Expected %s, but got %s.", ute.Field, ute.Type, ute.Value),
-			"code":    "WRONG_TYPE",
-		})
+		return ErrorResponse(NewValidationError(ute.Field, WrongTypeCode, "", Arg("expected", ute.Type), Arg("actual", ute.Value)), locale)
 	default:
-		log.Println("Unexpected error: " + err.Error())
-		WriteResponse(w, 500, map[string]string{"message": "Sorry, something went wrong on our end. Try again later!"})
+		return InternalErr.ResponseCode(), ErrorEnvelope{Code: InternalErr.Code, Message: localizedMessage(locale, InternalErr.Code, InternalErr.Message)}
 	}
 }
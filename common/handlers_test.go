@@ -25,7 +25,7 @@ func TestWriteErrorResponse(t *testing.T) {
 		},
 		{
 			desc:         "common.ValidationError",
-			inputErr:     NewValidationError("FIELDNAME", "FIELDCODE", "FIELDMESSAGE"),
+			inputErr:     NewValidationError("FIELDNAME", MissingCode, "name"),
 			expectedCode: 422,
 			expectedBody: map[string]interface{}{
 				"message": "One or more fields was either missing or invalid.",
@@ -33,25 +33,60 @@ func TestWriteErrorResponse(t *testing.T) {
 				"fields": []interface{}{
 					map[string]interface{}{
 						"fieldName": "FIELDNAME",
-						"code":      "FIELDCODE",
-						"message":   "FIELDMESSAGE",
+						"code":      "MISSING",
+						"message":   "You must provide a name.",
 					},
 				},
 			},
 		},
+		{
+			desc:         "common.InUseErr",
+			inputErr:     InUseErr,
+			expectedCode: 409,
+			expectedBody: map[string]interface{}{"message": InUseErr.Message, "code": "IN_USE"},
+		},
+		{
+			desc:         "common.ForbiddenErr",
+			inputErr:     ForbiddenErr,
+			expectedCode: 403,
+			expectedBody: map[string]interface{}{"message": ForbiddenErr.Message, "code": "FORBIDDEN"},
+		},
+		{
+			desc:         "common.RateLimitedErr",
+			inputErr:     RateLimitedErr,
+			expectedCode: 429,
+			expectedBody: map[string]interface{}{"message": RateLimitedErr.Message, "code": "RATE_LIMITED"},
+		},
 		{
 			desc:         "errorString (builtin)",
 			inputErr:     errors.New("laksjd"),
 			expectedCode: 500,
-			expectedBody: map[string]interface{}{"message": "Sorry, something went wrong on our end. Try again later!"},
+			expectedBody: map[string]interface{}{"message": "Sorry, something went wrong on our end. Try again later!", "code": "INTERNAL_ERROR"},
+		},
+		{
+			desc:         "common.StaleError",
+			inputErr:     NewStaleError(map[string]interface{}{"id": "ID", "version": 2}),
+			expectedCode: 412,
+			expectedBody: map[string]interface{}{
+				"message": PreconditionFailedErr.Message,
+				"code":    "PRECONDITION_FAILED",
+				"current": map[string]interface{}{"id": "ID", "version": float64(2)},
+			},
 		},
 		{
 			desc:         "*json.UnmarshalTypeError",
 			inputErr:     &json.UnmarshalTypeError{Field: "FIELDNAME", Value: "ACTUAL", Type: reflect.TypeOf("EXPECTED")},
 			expectedCode: 422,
 			expectedBody: map[string]interface{}{
-				"message": "Got value of wrong type for FIELDNAME. Expected string, but got ACTUAL.",
-				"code":    "WRONG_TYPE",
+				"message": "One or more fields was either missing or invalid.",
+				"code":    "INVALID_DATA",
+				"fields": []interface{}{
+					map[string]interface{}{
+						"fieldName": "FIELDNAME",
+						"code":      "WRONG_TYPE",
+						"message":   "Got a value of the wrong type. Expected string, but got ACTUAL.",
+					},
+				},
 			},
 		},
 	} {
@@ -64,6 +99,147 @@ func TestWriteErrorResponse(t *testing.T) {
 	}
 }
 
+func TestWriteErrorResponseIncludesRequestID(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set(RequestIDHeader, "REQUEST-ID")
+	WriteErrorResponse(recorder, NotFoundErr)
+	_, body := getCodeAndData(recorder)
+	assert.Equal(t, "REQUEST-ID", body["requestId"])
+}
+
+func TestWriteErrorResponseLocalized(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set(ContentLanguageHeader, "es")
+	WriteErrorResponse(recorder, CombineErrors(
+		NewValidationError("password", TooShortCode, "password", Arg("min", 8)),
+		NewValidationError("color", UnknownFieldCode, ""),
+	))
+	_, body := getCodeAndData(recorder)
+	assert.Equal(t, map[string]interface{}{
+		"message": "Faltan uno o más campos, o no son válidos.",
+		"code":    "INVALID_DATA",
+		"fields": []interface{}{
+			map[string]interface{}{"fieldName": "password", "code": "TOO_SHORT", "message": "Tu contraseña debe tener al menos 8 caracteres."},
+			map[string]interface{}{"fieldName": "color", "code": "UNKNOWN_FIELD", "message": "No reconocemos este campo."},
+		},
+	}, body)
+
+	recorder = httptest.NewRecorder()
+	recorder.Header().Set(ContentLanguageHeader, "es")
+	WriteErrorResponse(recorder, NotFoundErr)
+	_, body = getCodeAndData(recorder)
+	assert.Equal(t, "No pudimos encontrar uno o más de los recursos a los que hiciste referencia.", body["message"])
+}
+
+func TestWriteErrorResponseStaleETag(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteErrorResponse(recorder, NewStaleError(&versionedThing{Version: 7}))
+	assert.Equal(t, 412, recorder.Code)
+	assert.Equal(t, `"7"`, recorder.Result().Header.Get("ETag"))
+}
+
+func TestStatusFor(t *testing.T) {
+	for _, testCase := range []struct {
+		err      *BasicError
+		expected int
+	}{
+		{ParseErr, 400},
+		{UnauthorizedErr, 401},
+		{ForbiddenErr, 403},
+		{NotFoundErr, 404},
+		{InUseErr, 409},
+		{PreconditionFailedErr, 412},
+		{invalidDataErr, 422},
+		{RateLimitedErr, 429},
+		{InternalErr, 500},
+		{UnavailableErr, 503},
+	} {
+		assert.Equal(t, testCase.expected, StatusFor(testCase.err.Code), "CASE: %s", testCase.err.Code)
+	}
+	assert.Equal(t, 500, StatusFor("NEVER_REGISTERED"))
+}
+
+func TestNewErrorRejectsConflictingStatus(t *testing.T) {
+	assert.NotPanics(t, func() { NewError(404, NotFoundErr.Code, "Same status, different words.") })
+	assert.Panics(t, func() { NewError(400, NotFoundErr.Code, "Different status.") })
+}
+
+func TestWriteResponseEncodeFailure(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteResponse(recorder, 200, map[string]interface{}{"bad": make(chan int)})
+	code, body := getCodeAndData(recorder)
+	assert.Equal(t, 500, code)
+	assert.Equal(t, "INTERNAL_ERROR", body["code"])
+}
+
+type versionedThing struct {
+	Version int `json:"version"`
+}
+
+func (v versionedThing) ETag() string {
+	return `"7"`
+}
+
+func TestWriteResponseETag(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteResponse(recorder, 200, &versionedThing{Version: 7})
+	assert.Equal(t, `"7"`, recorder.Result().Header.Get("ETag"))
+
+	recorder = httptest.NewRecorder()
+	WriteResponse(recorder, 200, map[string]string{})
+	assert.Equal(t, "", recorder.Result().Header.Get("ETag"))
+}
+
+type warnedThing struct {
+	Warnings []InvalidField `json:"warnings"`
+}
+
+func (w *warnedThing) Localize(locale string) {
+	w.Warnings = LocalizeFields(w.Warnings, locale)
+}
+
+func TestWriteResponseLocalizesWarnings(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set(ContentLanguageHeader, "es")
+	WriteResponse(recorder, 201, &warnedThing{Warnings: []InvalidField{NewWarning("Balance", NegativeBalanceCode, "")}})
+	assert.Equal(t, `{"warnings":[{"fieldName":"Balance","code":"NEGATIVE_BALANCE","message":"Presupuestaste más que tus ingresos, así que el saldo es negativo."}]}`+"\n", recorder.Body.String())
+}
+
+func TestWantsExpansion(t *testing.T) {
+	for _, testCase := range []struct {
+		query    string
+		expected bool
+	}{
+		{"", false},
+		{"?expand=category", true},
+		{"?expand=other,category", true},
+		{"?expand=other&expand=category", true},
+		{"?expand=categories", false},
+	} {
+		r := httptest.NewRequest("GET", "/v1/plans"+testCase.query, nil)
+		assert.Equal(t, testCase.expected, WantsExpansion(r, "category"), "CASE: %s", testCase.query)
+	}
+}
+
+func TestWantsDryRun(t *testing.T) {
+	for _, testCase := range []struct {
+		query       string
+		expected    bool
+		expectedErr bool
+	}{
+		{"", false, false},
+		{"?dryRun=true", true, false},
+		{"?dryRun=1", true, false},
+		{"?dryRun=false", false, false},
+		{"?dryRun=maybe", false, true},
+	} {
+		r := httptest.NewRequest("POST", "/v1/plans"+testCase.query, nil)
+		actual, err := WantsDryRun(r)
+		assert.Equal(t, testCase.expected, actual, "CASE: %s", testCase.query)
+		assert.Equal(t, testCase.expectedErr, err != nil, "CASE: %s", testCase.query)
+	}
+}
+
 func getCodeAndData(recorder *httptest.ResponseRecorder) (int, map[string]interface{}) {
 	result := recorder.Result()
 	data := map[string]interface{}{}
@@ -73,3 +249,12 @@ func getCodeAndData(recorder *httptest.ResponseRecorder) (int, map[string]interf
 	}
 	return result.StatusCode, data
 }
+
+func TestErrorResponseCountsValidationErrors(t *testing.T) {
+	ErrorResponse(CombineErrors(
+		NewValidationError("a", "COUNTED_CODE", ""),
+		NewValidationError("b", "COUNTED_CODE", ""),
+	), DefaultLocale)
+
+	assert.Equal(t, 2.0, validationErrors.Value("COUNTED_CODE"))
+}
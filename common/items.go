@@ -0,0 +1,148 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// These helpers address a single line item by its "id" within one of a document's arrays (e.g. a plan's bills). They work on the document's JSON representation, so they don't care which type of line item the array holds; services decode the edited document back into their model and validate it as usual.
+
+// FindLineItem returns the line item in the document's section whose ID matches itemID.
+func FindLineItem(doc interface{}, section, itemID string) (map[string]interface{}, error) {
+	docObject, err := toJSONObject(doc)
+	if err != nil {
+		return nil, err
+	}
+	items, idx := findLineItem(docObject, section, itemID)
+	if idx < 0 {
+		return nil, NotFoundErr
+	}
+	return items[idx].(map[string]interface{}), nil
+}
+
+// LineItem is a single line item as we send it to clients: just the item, but with the ETag of the document it belongs to, since that's the version If-Match has to name to change it.
+type LineItem struct {
+	fields map[string]interface{}
+	etag   string
+}
+
+// ETag is the ETag of the document the line item belongs to.
+func (item LineItem) ETag() string {
+	return item.etag
+}
+
+// MarshalJSON writes the line item by itself.
+func (item LineItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(item.fields)
+}
+
+// FindTaggedLineItem is like FindLineItem, but the result carries the document's ETag.
+func FindTaggedLineItem(doc tagged, section, itemID string) (LineItem, error) {
+	fields, err := FindLineItem(doc, section, itemID)
+	if err != nil {
+		return LineItem{}, err
+	}
+	return LineItem{fields, doc.ETag()}, nil
+}
+
+// ReplaceLineItem replaces the matching line item with the JSON object provided, keeping its ID, and decodes the edited document into result.
+func ReplaceLineItem(doc interface{}, section, itemID string, item []byte, result interface{}) error {
+	return editLineItem(doc, section, itemID, result, func(current map[string]interface{}) (interface{}, error) {
+		replacement, err := decodeJSONValue(item)
+		if err != nil {
+			return nil, ParseErr
+		}
+		replacementObject, ok := replacement.(map[string]interface{})
+		if !ok {
+			return nil, ParseErr
+		}
+		replacementObject["id"] = current["id"]
+		return replacementObject, nil
+	})
+}
+
+// PatchLineItem applies a JSON merge patch to the matching line item, keeping its ID, and decodes the edited document into result.
+func PatchLineItem(doc interface{}, section, itemID string, patch []byte, result interface{}) error {
+	return editLineItem(doc, section, itemID, result, func(current map[string]interface{}) (interface{}, error) {
+		patchValue, err := decodeJSONValue(patch)
+		if err != nil {
+			return nil, ParseErr
+		}
+		patchedObject, ok := mergeValue(current, patchValue).(map[string]interface{})
+		if !ok {
+			return nil, ParseErr
+		}
+		patchedObject["id"] = current["id"]
+		return patchedObject, nil
+	})
+}
+
+// RemoveLineItem removes the matching line item and decodes the edited document into result.
+func RemoveLineItem(doc interface{}, section, itemID string, result interface{}) error {
+	return editLineItem(doc, section, itemID, result, func(current map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	})
+}
+
+// Finds the line item, swaps it with whatever edit returns (or removes it if edit returns nil), and decodes the document into result.
+func editLineItem(doc interface{}, section, itemID string, result interface{}, edit func(map[string]interface{}) (interface{}, error)) error {
+	docObject, err := toJSONObject(doc)
+	if err != nil {
+		return err
+	}
+	items, idx := findLineItem(docObject, section, itemID)
+	if idx < 0 {
+		return NotFoundErr
+	}
+
+	edited, err := edit(items[idx].(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	if edited == nil {
+		docObject[section] = append(items[:idx], items[idx+1:]...)
+	} else {
+		items[idx] = edited
+	}
+
+	editedJSON, err := json.Marshal(docObject)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(editedJSON, result)
+}
+
+// Returns the section's items and the index of the one with a matching ID, or -1 if there isn't one.
+func findLineItem(docObject map[string]interface{}, section, itemID string) ([]interface{}, int) {
+	items, ok := docObject[section].([]interface{})
+	if !ok {
+		return nil, -1
+	}
+	for idx, item := range items {
+		itemObject, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := itemObject["id"].(string); ok && strings.EqualFold(id, itemID) {
+			return items, idx
+		}
+	}
+	return items, -1
+}
+
+// Round-trips any value through JSON to get a generic object we can edit.
+func toJSONObject(doc interface{}) (map[string]interface{}, error) {
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeJSONValue(docJSON)
+	if err != nil {
+		return nil, err
+	}
+	docObject, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, ParseErr
+	}
+	return docObject, nil
+}
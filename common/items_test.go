@@ -0,0 +1,96 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type itemsDoc struct {
+	Name  string      `json:"name"`
+	Bills []itemsBill `json:"bills"`
+}
+
+type itemsBill struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Amount int    `json:"amount"`
+}
+
+type taggedItemsDoc struct {
+	itemsDoc
+}
+
+func (doc taggedItemsDoc) ETag() string {
+	return `"4"`
+}
+
+var itemsInput = itemsDoc{
+	Name: "plan",
+	Bills: []itemsBill{
+		{ID: "aaa", Name: "rent", Amount: 1000},
+		{ID: "bbb", Name: "power", Amount: 100},
+	},
+}
+
+func TestFindLineItem(t *testing.T) {
+	item, err := FindLineItem(itemsInput, "bills", "BBB")
+	assert.Nil(t, err)
+	assert.Equal(t, "power", item["name"])
+
+	_, err = FindLineItem(itemsInput, "bills", "ccc")
+	assert.Equal(t, NotFoundErr, err)
+
+	_, err = FindLineItem(itemsInput, "expenses", "aaa")
+	assert.Equal(t, NotFoundErr, err)
+}
+
+func TestFindTaggedLineItem(t *testing.T) {
+	doc := taggedItemsDoc{itemsInput}
+	item, err := FindTaggedLineItem(doc, "bills", "bbb")
+	assert.Nil(t, err)
+	assert.Equal(t, `"4"`, item.ETag())
+	data, err := json.Marshal(item)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":"bbb","name":"power","amount":100}`, string(data))
+
+	_, err = FindTaggedLineItem(doc, "bills", "ccc")
+	assert.Equal(t, NotFoundErr, err)
+}
+
+func TestReplaceLineItem(t *testing.T) {
+	result := itemsDoc{}
+	err := ReplaceLineItem(itemsInput, "bills", "aaa", []byte(`{"id":"zzz","name":"mortgage"}`), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, []itemsBill{
+		{ID: "aaa", Name: "mortgage"},
+		{ID: "bbb", Name: "power", Amount: 100},
+	}, result.Bills)
+
+	err = ReplaceLineItem(itemsInput, "bills", "aaa", []byte(`[]`), &result)
+	assert.Equal(t, ParseErr, err)
+}
+
+func TestPatchLineItem(t *testing.T) {
+	result := itemsDoc{}
+	err := PatchLineItem(itemsInput, "bills", "bbb", []byte(`{"amount":150}`), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, []itemsBill{
+		{ID: "aaa", Name: "rent", Amount: 1000},
+		{ID: "bbb", Name: "power", Amount: 150},
+	}, result.Bills)
+	assert.Equal(t, "plan", result.Name)
+}
+
+func TestRemoveLineItem(t *testing.T) {
+	result := itemsDoc{}
+	err := RemoveLineItem(itemsInput, "bills", "aaa", &result)
+	assert.Nil(t, err)
+	assert.Equal(t, []itemsBill{
+		{ID: "bbb", Name: "power", Amount: 100},
+	}, result.Bills)
+
+	err = RemoveLineItem(itemsInput, "bills", "ccc", &result)
+	assert.Equal(t, NotFoundErr, err)
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level says how important a log line is. Lines below the configured level aren't written.
+type Level int
+
+// These are the levels, from least to most important.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// Satisfies the `fmt.Stringer` interface; returns the name used in log lines.
+func (level Level) String() string {
+	if level < LevelDebug || level > LevelError {
+		return "unknown"
+	}
+	return levelNames[level]
+}
+
+// ParseLevel reads a level's name, like "warn".
+func ParseLevel(name string) (Level, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for idx, known := range levelNames {
+		if name == known {
+			return Level(idx), true
+		}
+	}
+	return LevelInfo, false
+}
+
+// RequestIDHeader carries the ID that ties together every log line about a request. Clients may send their own; otherwise, we make one up. Either way, it's echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// Logger writes log lines as JSON objects, one per line, along with whatever fields it carries.
+type Logger struct {
+	fields map[string]interface{}
+}
+
+// Log is the logger for anything that isn't part of handling a request.
+var Log = Logger{}
+
+var output = struct {
+	sync.Mutex
+	w     io.Writer
+	level Level
+}{w: os.Stdout, level: LevelInfo}
+
+// SetLogOutput changes where log lines are written. This is mostly useful for tests.
+func SetLogOutput(w io.Writer) {
+	output.Lock()
+	defer output.Unlock()
+	output.w = w
+}
+
+// SetLogLevel changes the least important level that gets written.
+func SetLogLevel(level Level) {
+	output.Lock()
+	defer output.Unlock()
+	output.level = level
+}
+
+// With returns a copy of the logger that adds the field to every line.
+func (l Logger) With(key string, value interface{}) Logger {
+	fields := make(map[string]interface{}, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return Logger{fields: fields}
+}
+
+// Debug writes a line that's only useful when tracking down a problem.
+func (l Logger) Debug(format string, args ...interface{}) {
+	l.write(LevelDebug, format, args...)
+}
+
+// Info writes a line about something routine.
+func (l Logger) Info(format string, args ...interface{}) {
+	l.write(LevelInfo, format, args...)
+}
+
+// Warn writes a line about something that went wrong, but that we could carry on from.
+func (l Logger) Warn(format string, args ...interface{}) {
+	l.write(LevelWarn, format, args...)
+}
+
+// Error writes a line about something that went wrong and affected a client.
+func (l Logger) Error(format string, args ...interface{}) {
+	l.write(LevelError, format, args...)
+}
+
+func (l Logger) write(level Level, format string, args ...interface{}) {
+	output.Lock()
+	defer output.Unlock()
+	if level < output.level {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(l.fields)+3)
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = fmt.Sprintf(format, args...)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		// One of the fields can't be written as JSON; the message is still worth having.
+		line, _ = json.Marshal(map[string]interface{}{"time": entry["time"], "level": entry["level"], "msg": entry["msg"]})
+	}
+	output.w.Write(append(line, '\n'))
+}
+
+// LogWriter writes each line it's given as a log line at this level. It lets code that expects a standard library logger, like middleware, log the same way we do.
+func LogWriter(level Level) io.Writer {
+	return logWriter{level: level}
+}
+
+type logWriter struct {
+	level Level
+}
+
+// Satisfies the `io.Writer` interface; logs the text without its trailing newline.
+func (w logWriter) Write(p []byte) (int, error) {
+	Log.write(w.level, "%s", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the context that carries the request's ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// GetRequestID returns the request ID the context carries, or an empty string if it has none.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// LoggerFor returns a logger that tags each line with the ID of the request the context belongs to.
+func LoggerFor(ctx context.Context) Logger {
+	return requestLogger(GetRequestID(ctx))
+}
+
+func requestLogger(requestID string) Logger {
+	if requestID == "" {
+		return Log
+	}
+	return Log.With("requestId", requestID)
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Captures what's logged while the function runs, as one decoded JSON object per line.
+func captureLogs(t *testing.T, level Level, f func()) []map[string]interface{} {
+	buf := &bytes.Buffer{}
+	SetLogOutput(buf)
+	SetLogLevel(level)
+	f()
+	SetLogLevel(LevelInfo)
+	SetLogOutput(os.Stdout)
+
+	lines := make([]map[string]interface{}, 0)
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		line := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal([]byte(raw), &line), "CASE: %s", raw)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestParseLevel(t *testing.T) {
+	for idx, name := range []string{"debug", "info", " WARN ", "error"} {
+		level, ok := ParseLevel(name)
+		assert.True(t, ok, "CASE: %s", name)
+		assert.Equal(t, Level(idx), level, "CASE: %s", name)
+	}
+	_, ok := ParseLevel("loud")
+	assert.False(t, ok)
+}
+
+func TestLoggerWritesJSON(t *testing.T) {
+	lines := captureLogs(t, LevelInfo, func() {
+		Log.With("userId", "user-1").Warn("Couldn't do %s", "it")
+	})
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "warn", lines[0]["level"])
+	assert.Equal(t, "Couldn't do it", lines[0]["msg"])
+	assert.Equal(t, "user-1", lines[0]["userId"])
+	assert.NotEmpty(t, lines[0]["time"])
+}
+
+func TestLoggerSkipsLowerLevels(t *testing.T) {
+	lines := captureLogs(t, LevelWarn, func() {
+		Log.Debug("no")
+		Log.Info("no")
+		Log.Warn("yes")
+		Log.Error("yes")
+	})
+	assert.Len(t, lines, 2)
+}
+
+func TestLoggerWithDoesNotShareFields(t *testing.T) {
+	base := Log.With("a", 1)
+	base.With("b", 2)
+	lines := captureLogs(t, LevelInfo, func() { base.Info("hi") })
+	assert.Len(t, lines, 1)
+	assert.NotContains(t, lines[0], "b")
+}
+
+func TestLoggerFor(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	lines := captureLogs(t, LevelInfo, func() {
+		LoggerFor(ctx).Info("with")
+		LoggerFor(context.Background()).Info("without")
+		Caller{UserID: "user-1", RequestID: "req-2"}.Logger().Info("caller")
+	})
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "req-1", lines[0]["requestId"])
+	assert.NotContains(t, lines[1], "requestId")
+	assert.Equal(t, "req-2", lines[2]["requestId"])
+	assert.Equal(t, "user-1", lines[2]["userId"])
+}
+
+func TestLogWriter(t *testing.T) {
+	lines := captureLogs(t, LevelInfo, func() {
+		LogWriter(LevelError).Write([]byte("PANIC: oops\n"))
+	})
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Equal(t, "PANIC: oops", lines[0]["msg"])
+}
+
+func TestWriteErrorResponseLogsUnexpectedErrors(t *testing.T) {
+	lines := captureLogs(t, LevelInfo, func() {
+		recorder := httptest.NewRecorder()
+		recorder.Header().Set(RequestIDHeader, "req-1")
+		WriteErrorResponse(recorder, errors.New("disk on fire"))
+		WriteErrorResponse(httptest.NewRecorder(), NotFoundErr)
+	})
+	assert.Len(t, lines, 1, "only unexpected errors should be logged")
+	assert.Equal(t, "req-1", lines[0]["requestId"])
+	assert.Contains(t, lines[0]["msg"], "disk on fire")
+}
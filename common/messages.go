@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the language we fall back to when a client doesn't ask for one we have, or when a message hasn't been translated.
+const DefaultLocale = "en"
+
+// ContentLanguageHeader tells the client which locale an error's messages are in.
+const ContentLanguageHeader = "Content-Language"
+
+// catalogs holds the messages for each locale we ship, keyed by code. Where one message doesn't suit every use of a field code, a variant makes it more specific, keyed like "MISSING.password". English messages for errors defined with NewError come from their definitions, so only the other locales list them.
+var catalogs = map[string]map[string]string{
+	"en": englishMessages,
+	"es": spanishMessages,
+}
+
+// Locales lists the locales we ship messages for, with the default first.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{DefaultLocale}, locales...)
+}
+
+// MessageArg fills in a placeholder in a message, like {max}.
+type MessageArg struct {
+	Name  string
+	Value interface{}
+}
+
+// Arg is shorthand for a MessageArg.
+func Arg(name string, value interface{}) MessageArg {
+	return MessageArg{Name: name, Value: value}
+}
+
+// Message returns the message for the code and variant (which may be empty) in the locale, with its placeholders filled in. It falls back to the code's general message, then to English; it reports false if none of those exist.
+func Message(locale, code, variant string, args ...MessageArg) (string, bool) {
+	for _, catalog := range []map[string]string{catalogs[locale], catalogs[DefaultLocale]} {
+		if catalog == nil {
+			continue
+		}
+		if variant != "" {
+			if template, ok := catalog[code+"."+variant]; ok {
+				return fillIn(template, args), true
+			}
+		}
+		if template, ok := catalog[code]; ok {
+			return fillIn(template, args), true
+		}
+	}
+	return "", false
+}
+
+func fillIn(template string, args []MessageArg) string {
+	if len(args) == 0 {
+		return template
+	}
+	pairs := make([]string, 0, 2*len(args))
+	for _, arg := range args {
+		pairs = append(pairs, "{"+arg.Name+"}", fmt.Sprint(arg.Value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Returns the error code's message in the locale, or its English message if it hasn't been translated.
+func localizedMessage(locale, code, english string) string {
+	if message, ok := catalogs[locale][code]; ok {
+		return message
+	}
+	return english
+}
+
+// NegotiateLocale picks the locale that best suits an Accept-Language header, like "es-MX,es;q=0.9,en;q=0.8". Regional variants match their language. If we don't have any of the languages, it returns the default.
+func NegotiateLocale(acceptLanguage string) string {
+	best, bestQuality := DefaultLocale, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		pieces := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(pieces[0]))
+		quality := 1.0
+		for _, param := range pieces[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					parsed = 0
+				}
+				quality = parsed
+			}
+		}
+		language := strings.Split(tag, "-")[0]
+		if language == "*" {
+			language = DefaultLocale
+		}
+		// Earlier languages win ties, since that's the order the client listed them in.
+		if _, ok := catalogs[language]; ok && quality > bestQuality {
+			best, bestQuality = language, quality
+		}
+	}
+	return best
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of the context that carries the locale the client asked for.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// GetLocale returns the locale the context carries, or the default if it has none.
+func GetLocale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
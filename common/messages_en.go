@@ -0,0 +1,56 @@
+package common
+
+var englishMessages = map[string]string{
+	MissingCode:                      "You must provide this.",
+	MissingCode + ".date":            "You must provide a date.",
+	MissingCode + ".name":            "You must provide a name.",
+	MissingCode + ".email":           "You must provide an email address.",
+	MissingCode + ".password":        "You must provide a password.",
+	MissingCode + ".operations":      "You must provide at least one operation.",
+	MissingCode + ".operationId":     "You must provide the ID of the resource to {op}.",
+	MissingCode + ".operationBody":   "You must provide a body to {op} the resource.",
+	MissingCode + ".scopes":          "You must provide at least one scope.",
+	MissingCode + ".expires":         "You must say when the token expires.",
+	MissingCode + ".halfMonthDays":   "You must provide at least two days of the month.",
+	MissingCode + ".schedule":        "You must specify exactly one schedule: {choices}",
+	TooManyCode:                      "You provided too many of these.",
+	TooManyCode + ".operations":      "You can provide at most {max} operations.",
+	TooManyCode + ".schedule":        "You must specify exactly one schedule: {choices}",
+	BadEnumChoiceCode:                "You must provide one of these: {choices}",
+	BadDateCode:                      "Date must be in the format YYYY-MM-DD, and it must be a valid date.",
+	BadUUIDFormatCode:                "Double-check the ID you're trying to reference, because this one doesn't look right. It should be in the format of a UUID.",
+	NonexistentRefCode:               "There's nothing with this ID.",
+	NonexistentRefCode + ".category": "There's no category with this ID.",
+	NonexistentRefCode + ".bill":     "There's no bill with this ID in the budget.",
+	NonexistentRefCode + ".reassign": "You must provide the ID of another category to reassign this one's line items to.",
+	NumOutOfRangeCode:                "This is out of the allowed range.",
+	NumOutOfRangeCode + ".between":   "Must be between {min} and {max} (inclusive).",
+	NumOutOfRangeCode + ".negative":  "This can't be negative.",
+	NumOutOfRangeCode + ".future":    "This must be in the future.",
+	DuplicateCode:                    "This is already taken.",
+	DuplicateCode + ".registered":    "Someone already registered with this email address.",
+	DuplicateCode + ".member":        "Someone with this email address is already a member.",
+	DuplicateCode + ".invited":       "This email address was already invited.",
+	DuplicateCode + ".lineItemId":    "Each line item must have a unique ID.",
+	BadETagCode:                      "This must be an entity tag, like \"3\".",
+	UnknownFieldCode:                 "This isn't a field we recognize.",
+	ReadOnlyFieldCode:                "This field is set by the server, so you can't provide it.",
+	WrongTypeCode:                    "Got a value of the wrong type. Expected {expected}, but got {actual}.",
+	WrongTypeCode + ".text":          "Expected text.",
+	WrongTypeCode + ".object":        "Expected an object.",
+	WrongTypeCode + ".list":          "Expected a list.",
+	WrongTypeCode + ".boolean":       "Expected true or false.",
+	WrongTypeCode + ".integer":       "Expected a whole number.",
+	WrongTypeCode + ".unsigned":      "Expected a whole number that isn't negative.",
+	WrongTypeCode + ".number":        "Expected a number.",
+	WrongTypeCode + ".format":        "This isn't formatted correctly.",
+	CycleCode:                        "A category can't be placed under itself or one of its own subcategories.",
+	TooShortCode:                     "This is too short.",
+	TooShortCode + ".password":       "Your password must be at least {min} characters long.",
+	BadEmailCode:                     "This doesn't look like an email address.",
+	RequiredTextCode:                 "You must provide some text.",
+	RequiredTextCode + ".name":       "You must provide a name.",
+	NegativeBalanceCode:              "You've budgeted more than your income, so the balance is negative.",
+	ExceedsIncomeCode:                "Over a year, your bills, expenses, and savings add up to more than your income.",
+	DayNotInEveryMonthCode:           "Not every month has a day {day}. Double-check when this happens in shorter months.",
+}
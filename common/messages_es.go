@@ -0,0 +1,76 @@
+package common
+
+var spanishMessages = map[string]string{
+	MissingCode:                      "Debes proporcionar este dato.",
+	MissingCode + ".date":            "Debes proporcionar una fecha.",
+	MissingCode + ".name":            "Debes proporcionar un nombre.",
+	MissingCode + ".email":           "Debes proporcionar un correo electrónico.",
+	MissingCode + ".password":        "Debes proporcionar una contraseña.",
+	MissingCode + ".operations":      "Debes proporcionar al menos una operación.",
+	MissingCode + ".operationId":     "Debes proporcionar el ID del recurso para la operación {op}.",
+	MissingCode + ".operationBody":   "Debes proporcionar un cuerpo para la operación {op}.",
+	MissingCode + ".scopes":          "Debes proporcionar al menos un permiso.",
+	MissingCode + ".expires":         "Debes indicar cuándo vence el token.",
+	MissingCode + ".halfMonthDays":   "Debes proporcionar al menos dos días del mes.",
+	MissingCode + ".schedule":        "Debes especificar exactamente un calendario: {choices}",
+	TooManyCode:                      "Proporcionaste demasiados valores.",
+	TooManyCode + ".operations":      "Puedes proporcionar como máximo {max} operaciones.",
+	TooManyCode + ".schedule":        "Debes especificar exactamente un calendario: {choices}",
+	BadEnumChoiceCode:                "Debes proporcionar uno de estos valores: {choices}",
+	BadDateCode:                      "La fecha debe tener el formato AAAA-MM-DD y ser una fecha válida.",
+	BadUUIDFormatCode:                "Revisa el ID al que haces referencia, porque este no parece correcto. Debe tener el formato de un UUID.",
+	NonexistentRefCode:               "No hay nada con este ID.",
+	NonexistentRefCode + ".category": "No hay ninguna categoría con este ID.",
+	NonexistentRefCode + ".bill":     "No hay ninguna factura con este ID en el presupuesto.",
+	NonexistentRefCode + ".reassign": "Debes proporcionar el ID de otra categoría a la que reasignar las partidas de esta.",
+	NumOutOfRangeCode:                "Este valor está fuera del rango permitido.",
+	NumOutOfRangeCode + ".between":   "Debe estar entre {min} y {max} (ambos incluidos).",
+	NumOutOfRangeCode + ".negative":  "Este valor no puede ser negativo.",
+	NumOutOfRangeCode + ".future":    "Debe ser una fecha futura.",
+	DuplicateCode:                    "Este valor ya está en uso.",
+	DuplicateCode + ".registered":    "Alguien ya se registró con este correo electrónico.",
+	DuplicateCode + ".member":        "Alguien con este correo electrónico ya es miembro.",
+	DuplicateCode + ".invited":       "Este correo electrónico ya fue invitado.",
+	DuplicateCode + ".lineItemId":    "Cada partida debe tener un ID único.",
+	BadETagCode:                      "Debe ser una etiqueta de entidad, como \"3\".",
+	UnknownFieldCode:                 "No reconocemos este campo.",
+	ReadOnlyFieldCode:                "El servidor asigna este campo, así que no puedes proporcionarlo.",
+	WrongTypeCode:                    "El valor es de un tipo incorrecto. Se esperaba {expected}, pero se recibió {actual}.",
+	WrongTypeCode + ".text":          "Se esperaba texto.",
+	WrongTypeCode + ".object":        "Se esperaba un objeto.",
+	WrongTypeCode + ".list":          "Se esperaba una lista.",
+	WrongTypeCode + ".boolean":       "Se esperaba true o false.",
+	WrongTypeCode + ".integer":       "Se esperaba un número entero.",
+	WrongTypeCode + ".unsigned":      "Se esperaba un número entero que no sea negativo.",
+	WrongTypeCode + ".number":        "Se esperaba un número.",
+	WrongTypeCode + ".format":        "Este valor no tiene el formato correcto.",
+	CycleCode:                        "Una categoría no puede colocarse dentro de sí misma ni de una de sus subcategorías.",
+	TooShortCode:                     "Este valor es demasiado corto.",
+	TooShortCode + ".password":       "Tu contraseña debe tener al menos {min} caracteres.",
+	BadEmailCode:                     "Esto no parece un correo electrónico.",
+	RequiredTextCode:                 "Debes proporcionar algún texto.",
+	RequiredTextCode + ".name":       "Debes proporcionar un nombre.",
+	NegativeBalanceCode:              "Presupuestaste más que tus ingresos, así que el saldo es negativo.",
+	ExceedsIncomeCode:                "En un año, tus facturas, gastos y ahorros suman más que tus ingresos.",
+	DayNotInEveryMonthCode:           "No todos los meses tienen un día {day}. Revisa cuándo ocurre esto en los meses más cortos.",
+
+	"CANNOT_PARSE":                "No pudimos interpretar los datos que proporcionaste.",
+	"UNAUTHORIZED":                "Primero debes iniciar sesión. Proporciona un token válido en el encabezado Authorization, así: Bearer <token>",
+	"BAD_CREDENTIALS":             "Ese correo electrónico y esa contraseña no coinciden con ninguna cuenta.",
+	"FORBIDDEN":                   "Tu rol en este hogar no permite eso.",
+	"INSUFFICIENT_SCOPE":          "Los permisos de este token de acceso no permiten eso. Usa un token con los permisos adecuados o inicia sesión.",
+	"MISSING_TENANT":              "Indica con qué datos de inquilino estás trabajando en el encabezado del inquilino.",
+	"NOT_FOUND":                   "No pudimos encontrar uno o más de los recursos a los que hiciste referencia.",
+	"IDEMPOTENCY_KEY_REUSED":      "Esta Idempotency-Key ya se usó para otra solicitud. Usa una clave nueva para cada solicitud distinta.",
+	"IDEMPOTENCY_KEY_IN_PROGRESS": "Todavía se está procesando una solicitud con esta Idempotency-Key. Vuelve a intentarlo en un momento.",
+	"IN_USE":                      "Todavía se hace referencia a esto en otro lugar, así que no se puede eliminar. Reasigna o elimina esas referencias primero.",
+	"LAST_OWNER":                  "Un hogar siempre debe tener un propietario. Haz propietario a otra persona primero.",
+	"RATE_LIMITED":                "Hiciste demasiadas solicitudes. Espera un momento y vuelve a intentarlo.",
+	"BATCH_ABORTED":               "Esta operación no se intentó porque falló una operación anterior del lote.",
+	"INTERNAL_ERROR":              "Lo sentimos, algo salió mal de nuestro lado. ¡Vuelve a intentarlo más tarde!",
+	"UNAVAILABLE":                 "No podemos atender solicitudes en este momento. Vuelve a intentarlo en breve.",
+	"METHOD_NOT_ALLOWED":          "Esta ruta no admite ese método.",
+	"UNSUPPORTED_MEDIA_TYPE":      "Esta ruta no entiende ese Content-Type. Envía un JSON merge patch, como application/merge-patch+json.",
+	"PRECONDITION_FAILED":         "Esto cambió desde la última vez que lo obtuviste. Revisa la versión actual y vuelve a intentarlo.",
+	"INVALID_DATA":                "Faltan uno o más campos, o no son válidos.",
+}
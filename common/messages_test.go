@@ -0,0 +1,182 @@
+package common
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Reads the values of every field code constant (like MissingCode) declared in errors.go.
+func fieldCodeConstants(t *testing.T) map[string]string {
+	file, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Couldn't parse errors.go: %s", err)
+	}
+	codes := map[string]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for idx, name := range valueSpec.Names {
+				literal, ok := valueSpec.Values[idx].(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING || !strings.HasSuffix(name.Name, "Code") {
+					continue
+				}
+				codes[name.Name], _ = strconv.Unquote(literal.Value)
+			}
+		}
+	}
+	return codes
+}
+
+func TestEveryCodeHasMessages(t *testing.T) {
+	codes := fieldCodeConstants(t)
+	assert.Contains(t, codes, "MissingCode")
+	for _, locale := range Locales() {
+		for name, code := range codes {
+			assert.Contains(t, catalogs[locale], code, "%s (%s) has no %s message", name, code, locale)
+		}
+		if locale == DefaultLocale {
+			continue
+		}
+		for code := range errorStatuses {
+			assert.Contains(t, catalogs[locale], code, "error %s has no %s message", code, locale)
+		}
+	}
+}
+
+var placeholderPattern = regexp.MustCompile(`\{[a-zA-Z]+\}`)
+
+func placeholders(message string) []string {
+	found := placeholderPattern.FindAllString(message, -1)
+	sort.Strings(found)
+	return found
+}
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	for _, locale := range Locales()[1:] {
+		for key, english := range englishMessages {
+			translated, ok := catalogs[locale][key]
+			if assert.True(t, ok, "%s has no %s message", key, locale) {
+				assert.Equal(t, placeholders(english), placeholders(translated), "%s's %s message has different placeholders", key, locale)
+			}
+		}
+		for key := range catalogs[locale] {
+			if _, isError := errorStatuses[key]; !isError {
+				assert.Contains(t, englishMessages, key, "%s has a %s message, but no English one", key, locale)
+			}
+		}
+	}
+}
+
+// Finds every variant passed to NewValidationError throughout the project, and makes sure each has an English message, so no one sees a more general message by mistake.
+func TestVariantsHaveMessages(t *testing.T) {
+	codes := fieldCodeConstants(t)
+	found := 0
+	err := filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && (info.Name() == "vendor" || strings.HasPrefix(info.Name(), "_") || (strings.HasPrefix(info.Name(), ".") && path != "..")) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) < 3 {
+				return true
+			}
+			var name string
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				name = fun.Name
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			}
+			if name != "NewValidationError" {
+				return true
+			}
+			var codeName string
+			switch code := call.Args[1].(type) {
+			case *ast.Ident:
+				codeName = code.Name
+			case *ast.SelectorExpr:
+				codeName = code.Sel.Name
+			}
+			variant, ok := call.Args[2].(*ast.BasicLit)
+			code, known := codes[codeName]
+			if !ok || !known || variant.Value == `""` {
+				return true
+			}
+			unquoted, _ := strconv.Unquote(variant.Value)
+			assert.Contains(t, englishMessages, code+"."+unquoted, "%s uses a variant with no message", path)
+			found++
+			return true
+		})
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, found, "should have found some variants")
+}
+
+func TestMessage(t *testing.T) {
+	for _, testCase := range []struct {
+		locale, code, variant string
+		args                  []MessageArg
+		expected              string
+		expectedOK            bool
+	}{
+		{"en", MissingCode, "", nil, "You must provide this.", true},
+		{"en", MissingCode, "password", nil, "You must provide a password.", true},
+		{"es", MissingCode, "password", nil, "Debes proporcionar una contraseña.", true},
+		{"en", MissingCode, "unheardOf", nil, "You must provide this.", true},
+		{"fr", MissingCode, "password", nil, "You must provide a password.", true},
+		{"en", TooManyCode, "operations", []MessageArg{Arg("max", 100)}, "You can provide at most 100 operations.", true},
+		{"es", NumOutOfRangeCode, "between", []MessageArg{Arg("min", 1), Arg("max", 31)}, "Debe estar entre 1 y 31 (ambos incluidos).", true},
+		{"en", "NEVER_DEFINED", "", nil, "", false},
+	} {
+		actual, ok := Message(testCase.locale, testCase.code, testCase.variant, testCase.args...)
+		assert.Equal(t, testCase.expected, actual, "CASE: %s %s.%s", testCase.locale, testCase.code, testCase.variant)
+		assert.Equal(t, testCase.expectedOK, ok, "CASE: %s %s.%s", testCase.locale, testCase.code, testCase.variant)
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	for _, testCase := range []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"es-MX", "es"},
+		{"ES-mx", "es"},
+		{"fr-CA, fr;q=0.9", "en"},
+		{"fr, es;q=0.5", "es"},
+		{"en;q=0.5, es;q=0.8", "es"},
+		{"es, en", "es"},
+		{"en, es", "en"},
+		{"es;q=0, en;q=0.1", "en"},
+		{"*", "en"},
+		{"es;q=nonsense", "en"},
+	} {
+		assert.Equal(t, testCase.expected, NegotiateLocale(testCase.header), "CASE: %q", testCase.header)
+	}
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"sync"
+
+	mgo "gopkg.in/mgo.v2"
+)
+
+// migration brings part of the database up to date, like creating the indexes a service relies on. Migrations run each time the server starts, so they must be safe to repeat.
+type migration struct {
+	name  string
+	apply func(session *mgo.Session) error
+}
+
+var migrations = struct {
+	sync.Mutex
+	all     []migration
+	applied map[string]bool
+}{applied: map[string]bool{}}
+
+// RegisterMigration adds a migration to run at startup. Services register theirs when they're loaded.
+func RegisterMigration(name string, apply func(session *mgo.Session) error) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	migrations.all = append(migrations.all, migration{name, apply})
+}
+
+// ApplyMigrations runs every migration that hasn't succeeded yet, in the order they were registered. Failures are logged, and the first is returned, but the rest are still attempted.
+func ApplyMigrations() error {
+	migrations.Lock()
+	defer migrations.Unlock()
+	session, err := dialMongo()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var first error
+	for _, m := range migrations.all {
+		if migrations.applied[m.name] {
+			continue
+		}
+		err = m.apply(session)
+		if err != nil {
+			Log.Error("Couldn't apply migration %s: %s", m.name, err.Error())
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		migrations.applied[m.name] = true
+		Log.Info("Applied migration %s.", m.name)
+	}
+	return first
+}
+
+// PendingMigrations lists the migrations that haven't succeeded yet.
+func PendingMigrations() []string {
+	migrations.Lock()
+	defer migrations.Unlock()
+	pending := make([]string, 0)
+	for _, m := range migrations.all {
+		if !migrations.applied[m.name] {
+			pending = append(pending, m.name)
+		}
+	}
+	return pending
+}
@@ -1,16 +1,225 @@
 package common
 
 import (
+	"sync"
+	"time"
+
 	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/metrics"
 	mgo "gopkg.in/mgo.v2"
 )
 
-// GetMongoSession connects to our single Mongo server. Eventually this will be split up by controller/service.
+// mongoTimeout limits how long we wait to connect when checking on the Mongo server, so a health check can't hang.
+const mongoTimeout = 2 * time.Second
+
+var (
+	mongoMutex   sync.Mutex
+	mongoSession *mgo.Session
+)
+
+// CopyMongoSession returns a session of its own for talking to our Mongo server. They're all copied from one connection, which is dialed the first time it's needed, so they share its pool of sockets; close each one when you're done with it. If we can't connect, it returns the error.
+func CopyMongoSession() (*mgo.Session, error) {
+	mongoMutex.Lock()
+	shared := mongoSession
+	mongoMutex.Unlock()
+	if shared != nil {
+		return shared.Copy(), nil
+	}
+
+	// Dial without holding the lock, so a slow server doesn't make every other request wait in line behind us.
+	session, err := dialMongo()
+	if err != nil {
+		return nil, err
+	}
+	// Only connecting has to be quick; queries get as long as mgo.Dial would give them.
+	session.SetSyncTimeout(time.Minute)
+	session.SetSocketTimeout(time.Minute)
+	mongoMutex.Lock()
+	defer mongoMutex.Unlock()
+	if mongoSession == nil {
+		mongoSession = session
+	} else {
+		// Someone else connected while we were dialing; use theirs.
+		session.Close()
+	}
+	return mongoSession.Copy(), nil
+}
+
+// GetMongoSession is like CopyMongoSession, but if it can't connect, it panics with UnavailableErr, which the recovery middleware reports to the client.
 func GetMongoSession() *mgo.Session {
-	url := config.GetConfig().MongoURL
-	session, err := mgo.Dial(url)
+	session, err := CopyMongoSession()
 	if err != nil {
-		panic("Couldn't connect to the Mongo server at " + url)
+		Log.Error("Couldn't connect to the Mongo server: %s", err.Error())
+		panic(UnavailableErr)
 	}
 	return session
 }
+
+// Database returns one of our Mongo databases, with the configured prefix, if any, added to its name.
+func Database(session *mgo.Session, name string) *mgo.Database {
+	return session.DB(config.GetConfig().DatabasePrefix + name)
+}
+
+// Connects to our Mongo server, returning an error rather than panicking if it can't.
+func dialMongo() (*mgo.Session, error) {
+	return mgo.DialWithTimeout(config.GetConfig().MongoURL, mongoTimeout)
+}
+
+// PingMongo checks that we can reach our Mongo server.
+func PingMongo() error {
+	session, err := dialMongo()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return session.Ping()
+}
+
+// WaitForMongo keeps checking on our Mongo server until it can be reached, backing off between attempts, so we can start before it does. It gives up with the last error once the timeout passes.
+func WaitForMongo(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := 500 * time.Millisecond
+	for {
+		err := PingMongo()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		Log.Warn("Waiting for the Mongo server: %s", err.Error())
+		time.Sleep(delay)
+		if delay < 8*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+var mongoDuration = metrics.NewHistogram("zbbapi_mongo_operation_duration_seconds", "How long Mongo operations take, by collection and operation.", metrics.DefaultBuckets, "collection", "operation")
+
+// Collection is a Mongo collection that times each of its operations for our metrics. It can be used just like the *mgo.Collection it wraps.
+type Collection struct {
+	*mgo.Collection
+}
+
+// TimedCollection wraps the collection so its operations are timed.
+func TimedCollection(c *mgo.Collection) *Collection {
+	return &Collection{c}
+}
+
+// Records how long an operation on the collection took, since it started.
+func observeMongo(collection, operation string, start time.Time) {
+	mongoDuration.Observe(time.Since(start).Seconds(), collection, operation)
+}
+
+// Find prepares a timed query. Nothing is sent to Mongo until its results are asked for.
+func (c *Collection) Find(query interface{}) *Query {
+	return &Query{c.Collection.Find(query), c.Name}
+}
+
+// FindId prepares a timed query for the document with this ID.
+func (c *Collection) FindId(id interface{}) *Query {
+	return &Query{c.Collection.FindId(id), c.Name}
+}
+
+// Count counts every document in the collection.
+func (c *Collection) Count() (int, error) {
+	defer observeMongo(c.Name, "count", time.Now())
+	return c.Collection.Count()
+}
+
+// Insert adds the documents.
+func (c *Collection) Insert(docs ...interface{}) error {
+	defer observeMongo(c.Name, "insert", time.Now())
+	return c.Collection.Insert(docs...)
+}
+
+// Update replaces or modifies the single document matching the selector.
+func (c *Collection) Update(selector, update interface{}) error {
+	defer observeMongo(c.Name, "update", time.Now())
+	return c.Collection.Update(selector, update)
+}
+
+// UpdateId replaces or modifies the document with this ID.
+func (c *Collection) UpdateId(id, update interface{}) error {
+	defer observeMongo(c.Name, "update", time.Now())
+	return c.Collection.UpdateId(id, update)
+}
+
+// Upsert replaces or modifies the single document matching the selector, inserting it if there isn't one.
+func (c *Collection) Upsert(selector, update interface{}) (*mgo.ChangeInfo, error) {
+	defer observeMongo(c.Name, "upsert", time.Now())
+	return c.Collection.Upsert(selector, update)
+}
+
+// Remove deletes the single document matching the selector.
+func (c *Collection) Remove(selector interface{}) error {
+	defer observeMongo(c.Name, "remove", time.Now())
+	return c.Collection.Remove(selector)
+}
+
+// RemoveId deletes the document with this ID.
+func (c *Collection) RemoveId(id interface{}) error {
+	defer observeMongo(c.Name, "remove", time.Now())
+	return c.Collection.RemoveId(id)
+}
+
+// RemoveAll deletes every document matching the selector.
+func (c *Collection) RemoveAll(selector interface{}) (*mgo.ChangeInfo, error) {
+	defer observeMongo(c.Name, "remove", time.Now())
+	return c.Collection.RemoveAll(selector)
+}
+
+// Query is a Mongo query that times fetching its results for our metrics. It can be used just like the *mgo.Query it wraps.
+type Query struct {
+	*mgo.Query
+	collection string
+}
+
+// Sort orders the results by these fields.
+func (q *Query) Sort(fields ...string) *Query {
+	q.Query.Sort(fields...)
+	return q
+}
+
+// Limit returns at most this many results.
+func (q *Query) Limit(n int) *Query {
+	q.Query.Limit(n)
+	return q
+}
+
+// Skip leaves out this many results from the start.
+func (q *Query) Skip(n int) *Query {
+	q.Query.Skip(n)
+	return q
+}
+
+// Select only returns these fields of each result.
+func (q *Query) Select(selector interface{}) *Query {
+	q.Query.Select(selector)
+	return q
+}
+
+// One fetches the first result.
+func (q *Query) One(result interface{}) error {
+	defer observeMongo(q.collection, "find", time.Now())
+	return q.Query.One(result)
+}
+
+// All fetches every result.
+func (q *Query) All(result interface{}) error {
+	defer observeMongo(q.collection, "find", time.Now())
+	return q.Query.All(result)
+}
+
+// Count counts the results.
+func (q *Query) Count() (int, error) {
+	defer observeMongo(q.collection, "count", time.Now())
+	return q.Query.Count()
+}
+
+// Apply finds the first result and changes it.
+func (q *Query) Apply(change mgo.Change, result interface{}) (*mgo.ChangeInfo, error) {
+	defer observeMongo(q.collection, "findAndModify", time.Now())
+	return q.Query.Apply(change, result)
+}
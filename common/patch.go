@@ -0,0 +1,77 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+)
+
+// MergePatchContentType is the media type RFC 7396 gives JSON merge patches.
+const MergePatchContentType = "application/merge-patch+json"
+
+// CheckMergePatchType makes sure a request's Content-Type says its body is a merge patch. Plain JSON is accepted too, since that's what a merge patch is written in.
+func CheckMergePatchType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+		return UnsupportedMediaTypeErr
+	}
+	return nil
+}
+
+// MergePatch applies an RFC 7396 JSON merge patch to the original document and returns the patched document.
+func MergePatch(original, patch []byte) ([]byte, error) {
+	originalValue, err := decodeJSONValue(original)
+	if err != nil {
+		return nil, err
+	}
+	patchValue, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(originalValue, patchValue))
+}
+
+// ApplyMergePatch applies the merge patch to the JSON representation of the original and decodes the patched document into result.
+func ApplyMergePatch(original interface{}, patch []byte, result interface{}) error {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	patchedJSON, err := MergePatch(originalJSON, patch)
+	if err != nil {
+		return ParseErr
+	}
+	return json.Unmarshal(patchedJSON, result)
+}
+
+// Decodes any JSON value, keeping numbers as they were written so integer amounts don't get rounded through float64.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Follows the MergePatch pseudocode from RFC 7396, section 2.
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergeValue(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
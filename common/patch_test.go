@@ -0,0 +1,84 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// These come from the examples in RFC 7396, appendix A.
+	for _, testCase := range []struct {
+		original string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Large integer amounts shouldn't lose precision.
+		{`{"amount":1}`, `{"amount":90071992547409993}`, `{"amount":90071992547409993}`},
+	} {
+		actual, err := MergePatch([]byte(testCase.original), []byte(testCase.patch))
+		assert.Nil(t, err, "CASE: %s + %s, unexpected error", testCase.original, testCase.patch)
+		assert.JSONEq(t, testCase.expected, string(actual), "CASE: %s + %s, wrong result", testCase.original, testCase.patch)
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	_, err := MergePatch([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	assert.NotNil(t, err)
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	type thing struct {
+		Name   string `json:"name"`
+		Amount int    `json:"amount"`
+	}
+
+	result := thing{}
+	err := ApplyMergePatch(thing{Name: "rent", Amount: 100}, []byte(`{"amount":200}`), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, thing{Name: "rent", Amount: 200}, result)
+
+	err = ApplyMergePatch(thing{}, []byte(`not json`), &result)
+	assert.Equal(t, ParseErr, err)
+
+	err = ApplyMergePatch(thing{}, []byte(`{"amount":"lots"}`), &result)
+	_, ok := err.(*json.UnmarshalTypeError)
+	assert.True(t, ok, "expected a type error, got %#v", err)
+}
+
+func TestCheckMergePatchType(t *testing.T) {
+	for _, testCase := range []struct {
+		contentType string
+		allowed     bool
+	}{
+		{"application/merge-patch+json", true},
+		{"application/merge-patch+json; charset=utf-8", true},
+		{"application/json", true},
+		{"application/json-patch+json", false},
+		{"text/plain", false},
+		{"", false},
+	} {
+		err := CheckMergePatchType(testCase.contentType)
+		if testCase.allowed {
+			assert.Nil(t, err, "CASE: %q", testCase.contentType)
+		} else {
+			assert.Equal(t, UnsupportedMediaTypeErr, err, "CASE: %q", testCase.contentType)
+		}
+	}
+}
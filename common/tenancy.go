@@ -0,0 +1,9 @@
+package common
+
+import "gopkg.in/mgo.v2/bson"
+
+// ForTenant narrows a selector to documents belonging to one tenant, so no query can reach another tenant's data. A blank tenant matches nothing that was saved with one.
+func ForTenant(tenantID string, selector bson.M) bson.M {
+	selector["tenant"] = tenantID
+	return selector
+}
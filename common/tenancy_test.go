@@ -0,0 +1,23 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestForTenant(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		selector bson.M
+		expected bson.M
+	}{
+		{name: "everything", selector: bson.M{}, expected: bson.M{"tenant": "t1"}},
+		{name: "by ID", selector: bson.M{"_id": "abc"}, expected: bson.M{"_id": "abc", "tenant": "t1"}},
+		{name: "versioned", selector: VersionSelector("abc", 2), expected: bson.M{"_id": "abc", "version": 2, "tenant": "t1"}},
+		{name: "another tenant", selector: bson.M{"_id": "abc", "tenant": "t2"}, expected: bson.M{"_id": "abc", "tenant": "t1"}},
+	} {
+		assert.Equal(t, testCase.expected, ForTenant("t1", testCase.selector), "CASE: %s", testCase.name)
+	}
+}
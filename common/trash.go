@@ -0,0 +1,24 @@
+package common
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// NotDeleted narrows a selector to documents that aren't in the trash.
+func NotDeleted(selector bson.M) bson.M {
+	selector["deleted"] = nil
+	return selector
+}
+
+// InTrash narrows a selector to documents that are in the trash.
+func InTrash(selector bson.M) bson.M {
+	selector["deleted"] = bson.M{"$ne": nil}
+	return selector
+}
+
+// DeletedBefore selects documents that were moved to the trash before the cutoff.
+func DeletedBefore(cutoff time.Time) bson.M {
+	return bson.M{"deleted": bson.M{"$lt": cutoff}}
+}
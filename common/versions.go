@@ -0,0 +1,60 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AnyVersion is used when the client didn't send an If-Match header, so any current version may be updated.
+const AnyVersion = -1
+
+// PreconditionFailedErr is a reusable error for any time the client's If-Match doesn't match the current version.
+var PreconditionFailedErr = NewError(412, "PRECONDITION_FAILED", "This was changed since you last retrieved it. Review the current version and try again.")
+
+// StaleError is returned when a document changed since it was last read. It carries the current representation so the client can reconcile its changes.
+type StaleError struct {
+	BasicError
+	Current interface{}
+}
+
+// NewStaleError wraps the current representation of a document in a StaleError.
+func NewStaleError(current interface{}) *StaleError {
+	return &StaleError{
+		BasicError: *PreconditionFailedErr,
+		Current:    current,
+	}
+}
+
+// ParseIfMatch turns an If-Match header value (like `"3"`) into the version it refers to. An empty value or `*` means any version.
+func ParseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return AnyVersion, nil
+	}
+	header = strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, ParseErr
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 0 {
+		return 0, ParseErr
+	}
+	return version, nil
+}
+
+// VersionSelector selects a document by ID only if it's still at the given version, which lets an update act as an atomic compare-and-swap. Documents saved before versioning existed have no version, which we treat as zero.
+func VersionSelector(id interface{}, version int) bson.M {
+	if version == 0 {
+		return bson.M{
+			"_id":     id,
+			"version": bson.M{"$in": []interface{}{0, nil}},
+		}
+	}
+	return bson.M{
+		"_id":     id,
+		"version": version,
+	}
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	for _, testCase := range []struct {
+		header   string
+		expected int
+		err      error
+	}{
+		{header: "", expected: AnyVersion},
+		{header: "*", expected: AnyVersion},
+		{header: `"3"`, expected: 3},
+		{header: ` "12" `, expected: 12},
+		{header: `W/"4"`, expected: 4},
+		{header: `3`, err: ParseErr},
+		{header: `"three"`, err: ParseErr},
+		{header: `"-2"`, err: ParseErr},
+	} {
+		actual, err := ParseIfMatch(testCase.header)
+		assert.Equal(t, testCase.err, err, "CASE: %s, error mismatch", testCase.header)
+		if testCase.err == nil {
+			assert.Equal(t, testCase.expected, actual, "CASE: %s, version mismatch", testCase.header)
+		}
+	}
+}
+
+func TestVersionSelector(t *testing.T) {
+	assert.Equal(t, bson.M{"_id": "abc", "version": 2}, VersionSelector("abc", 2))
+	assert.Equal(t, bson.M{"_id": "abc", "version": bson.M{"$in": []interface{}{0, nil}}}, VersionSelector("abc", 0))
+}
@@ -1,24 +1,331 @@
 package config
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
+// RateLimit lets each client make Requests requests in a burst, refilling at that many per Per. Zero requests means there's no limit.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
 // Config contains all configuration used by the entire app.
 type Config struct {
 	MongoURL string
+	// MongoStartupTimeout is how long we wait for the Mongo server to come up when we start, before giving up.
+	MongoStartupTimeout time.Duration
+	// DatabasePrefix starts the name of every Mongo database we use, so several deployments can share a server.
+	DatabasePrefix string
+	// ListenAddress is where the server accepts connections, like ":8080".
+	ListenAddress string
+	// ReadTimeout, WriteTimeout, and IdleTimeout limit how long a connection can take to send its request, take to receive its response, and sit idle between requests. Zero means no limit.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout is how long requests that are still in progress get to finish once we're asked to stop.
+	ShutdownTimeout time.Duration
+	// TLSCertFile and TLSKeyFile, if both are set, make the server use HTTPS.
+	TLSCertFile string
+	TLSKeyFile  string
+	// IdempotencyWindow is how long we remember the response to a request made with an Idempotency-Key.
+	IdempotencyWindow time.Duration
+	// TrashRetention is how long deleted resources stay in the trash, where they can be restored, before they're purged.
+	TrashRetention time.Duration
+	// TrashSweepInterval is how often we check for resources that have been in the trash too long.
+	TrashSweepInterval time.Duration
+	// AuthSecret signs the tokens users get when they log in. Keep it secret, and keep it the same across restarts so tokens stay valid.
+	AuthSecret string
+	// TokenLifetime is how long a login lasts.
+	TokenLifetime time.Duration
+	// TenantHeader names a request header that says which tenant's data the caller is working with. Leave it empty to make each user their own tenant. Only set it behind a proxy that sets the header itself and never passes along the client's.
+	TenantHeader string
+	// ProxySecret is what that proxy sends in the X-Proxy-Secret header, so we know the tenant header came from it. It's required along with TenantHeader.
+	ProxySecret string
+	// RateLimits holds the rate limit for each class of request: "read", "write", and "auth" (registering and logging in).
+	RateLimits map[string]RateLimit
+	// RateLimitStore is where rate limits are tracked: "memory" for a single instance, or "mongo" to share them between instances.
+	RateLimitStore string
+	// LogLevel is the least important level of log line that gets written: "debug", "info", "warn", or "error".
+	LogLevel string
+}
+
+// defaults returns the configuration we use for anything that isn't set.
+func defaults() *Config {
+	return &Config{
+		MongoStartupTimeout: 2 * time.Minute,
+		ListenAddress:       ":8080",
+		ReadTimeout:         15 * time.Second,
+		WriteTimeout:        30 * time.Second,
+		IdleTimeout:         2 * time.Minute,
+		ShutdownTimeout:     30 * time.Second,
+		IdempotencyWindow:   24 * time.Hour,
+		TrashRetention:      30 * 24 * time.Hour,
+		TrashSweepInterval:  time.Hour,
+		TokenLifetime:       24 * time.Hour,
+		RateLimits: map[string]RateLimit{
+			"read":  {300, time.Minute},
+			"write": {60, time.Minute},
+			"auth":  {10, time.Minute},
+		},
+		RateLimitStore: "memory",
+		LogLevel:       "info",
+	}
 }
 
 var config *Config
-var once sync.Once
+var mutex sync.Mutex
 
-// GetConfig returns the populated, singleton instance of the configuration.
+// GetConfig returns the configuration main loaded. If it wasn't loaded, like in tests, it's the defaults with any environment variables applied.
 func GetConfig() *Config {
-	once.Do(func() {
-		config = &Config{
-			MongoURL: os.Getenv("MONGO_URL"),
+	mutex.Lock()
+	defer mutex.Unlock()
+	if config == nil {
+		loaded, err := load(nil, os.LookupEnv)
+		if err != nil {
+			log.Printf("Ignoring invalid configuration: %s", err.Error())
+			loaded = defaults()
 		}
-	})
+		config = loaded
+	}
 	return config
 }
+
+// Load reads the configuration from, in increasing order of precedence: the defaults, a YAML file (named by the -config flag or CONFIG_FILE), environment variables, and command-line flags. If every setting is valid, the result is what GetConfig returns from then on; otherwise, the error describes each problem.
+func Load(args []string) (*Config, error) {
+	loaded, err := load(args, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	config = loaded
+	return loaded, nil
+}
+
+// InvalidError lists every setting that was invalid, so they can all be fixed at once.
+type InvalidError []string
+
+// Satisfies the `error` interface; returns each problem.
+func (e InvalidError) Error() string {
+	return strings.Join(e, "; ")
+}
+
+func load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	flags := flag.NewFlagSet("zbbapi", flag.ContinueOnError)
+	configFile := flags.String("config", "", "A YAML file to read settings from, using the same names as these flags. Also CONFIG_FILE.")
+	for _, s := range settings {
+		flags.String(s.name, "", s.usage+" Also "+envName(s.name)+".")
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c := defaults()
+	var errs InvalidError
+	set := func(source, name, raw string) {
+		s, ok := settingNamed(name)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s (from %s): there's no such setting", name, source))
+			return
+		}
+		err := s.set(c, strings.TrimSpace(raw))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s (from %s): %s", name, source, err.Error()))
+		}
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			set(path, name, values[name])
+		}
+	}
+	for _, s := range settings {
+		if raw, ok := lookupEnv(envName(s.name)); ok && raw != "" {
+			set("the environment", s.name, raw)
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			set("flags", f.Name, f.Value.String())
+		}
+	})
+
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return c, nil
+}
+
+// Reads a flat YAML mapping of setting names to values.
+func readFile(path string) (map[string]string, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed := map[string]interface{}{}
+	err = yaml.Unmarshal(raw, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	values := make(map[string]string, len(parsed))
+	for name, value := range parsed {
+		switch value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("%s: %s should be a single value", path, name)
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// Checks what can't be checked one setting at a time.
+func (c *Config) validate() []string {
+	errs := make([]string, 0)
+	if c.ListenAddress == "" {
+		errs = append(errs, "listen-address: you must say where to listen")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, "tls-cert-file and tls-key-file: you must set both, or neither")
+	}
+	if c.TenantHeader != "" && c.ProxySecret == "" {
+		errs = append(errs, "proxy-secret: you must set it to use tenant-header")
+	}
+	if c.RateLimitStore != "memory" && c.RateLimitStore != "mongo" {
+		errs = append(errs, "rate-limit-store: must be memory or mongo")
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, "log-level: must be debug, info, warn, or error")
+	}
+	return errs
+}
+
+// setting is something that can be configured, named as it is in the config file and flags, like "listen-address". Its environment variable is the same in capitals, like LISTEN_ADDRESS.
+type setting struct {
+	name  string
+	usage string
+	set   func(c *Config, raw string) error
+}
+
+var settings = []setting{
+	{"mongo-url", "Where to find the Mongo server.", stringValue(func(c *Config) *string { return &c.MongoURL })},
+	{"mongo-startup-timeout", "How long to wait for the Mongo server when starting.", durationValue(func(c *Config) *time.Duration { return &c.MongoStartupTimeout }, false)},
+	{"database-prefix", "Starts the name of every Mongo database we use.", stringValue(func(c *Config) *string { return &c.DatabasePrefix })},
+	{"listen-address", "Where to accept connections.", stringValue(func(c *Config) *string { return &c.ListenAddress })},
+	{"read-timeout", "How long a client may take to send its request; 0 for no limit.", durationValue(func(c *Config) *time.Duration { return &c.ReadTimeout }, true)},
+	{"write-timeout", "How long a client may take to receive its response; 0 for no limit.", durationValue(func(c *Config) *time.Duration { return &c.WriteTimeout }, true)},
+	{"idle-timeout", "How long a connection may sit idle between requests; 0 for no limit.", durationValue(func(c *Config) *time.Duration { return &c.IdleTimeout }, true)},
+	{"shutdown-timeout", "How long requests in progress get to finish when stopping.", durationValue(func(c *Config) *time.Duration { return &c.ShutdownTimeout }, false)},
+	{"tls-cert-file", "A certificate to serve HTTPS with, along with tls-key-file.", stringValue(func(c *Config) *string { return &c.TLSCertFile })},
+	{"tls-key-file", "The private key for tls-cert-file.", stringValue(func(c *Config) *string { return &c.TLSKeyFile })},
+	{"idempotency-window", "How long to remember responses to requests with an Idempotency-Key.", durationValue(func(c *Config) *time.Duration { return &c.IdempotencyWindow }, false)},
+	{"trash-retention", "How long deleted resources stay in the trash.", durationValue(func(c *Config) *time.Duration { return &c.TrashRetention }, false)},
+	{"trash-sweep-interval", "How often to empty the trash of old resources.", durationValue(func(c *Config) *time.Duration { return &c.TrashSweepInterval }, false)},
+	{"auth-secret", "Signs login tokens. Prefer the environment variable or config file, since flags can be seen by other users.", stringValue(func(c *Config) *string { return &c.AuthSecret })},
+	{"token-lifetime", "How long a login lasts.", durationValue(func(c *Config) *time.Duration { return &c.TokenLifetime }, false)},
+	{"tenant-header", "A request header, set by a trusted proxy, naming the caller's tenant.", stringValue(func(c *Config) *string { return &c.TenantHeader })},
+	{"proxy-secret", "Sent by the proxy in X-Proxy-Secret, to prove it set tenant-header. Prefer the environment variable or config file.", stringValue(func(c *Config) *string { return &c.ProxySecret })},
+	{"rate-limit-read", "Rate limit for reads, like 300/1m, or off.", rateLimitValue("read")},
+	{"rate-limit-write", "Rate limit for writes, like 60/1m, or off.", rateLimitValue("write")},
+	{"rate-limit-auth", "Rate limit for registering and logging in, like 10/1m, or off.", rateLimitValue("auth")},
+	{"rate-limit-store", "Where to track rate limits: memory or mongo.", stringValue(func(c *Config) *string { return &c.RateLimitStore })},
+	{"log-level", "The least important log lines to write: debug, info, warn, or error.", stringValue(func(c *Config) *string { return &c.LogLevel })},
+}
+
+func settingNamed(name string) (setting, bool) {
+	for _, s := range settings {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// Names the environment variable for a setting, like LISTEN_ADDRESS for listen-address.
+func envName(name string) string {
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+func stringValue(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		*field(c) = raw
+		return nil
+	}
+}
+
+// Reads a duration like "24h". Only timeouts, where zero means no limit, may be zero.
+func durationValue(field func(c *Config) *time.Duration, zeroAllowed bool) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q isn't a duration like 30s or 24h", raw)
+		}
+		if duration < 0 || (duration == 0 && !zeroAllowed) {
+			return fmt.Errorf("%q must be longer than that", raw)
+		}
+		*field(c) = duration
+		return nil
+	}
+}
+
+func rateLimitValue(class string) func(*Config, string) error {
+	return func(c *Config, raw string) error {
+		limit, err := ParseRateLimit(raw)
+		if err != nil {
+			return fmt.Errorf("%q isn't a rate limit like 60/1m, or off", raw)
+		}
+		c.RateLimits[class] = limit
+		return nil
+	}
+}
+
+// ParseRateLimit reads a rate limit like "60/1m": 60 requests per minute. "off" means there's no limit.
+func ParseRateLimit(raw string) (RateLimit, error) {
+	if raw == "off" {
+		return RateLimit{}, nil
+	}
+	parts := strings.SplitN(raw, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, strconv.ErrSyntax
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests < 0 {
+		return RateLimit{}, strconv.ErrSyntax
+	}
+	per, err := time.ParseDuration(parts[1])
+	if err != nil || per <= 0 {
+		return RateLimit{}, strconv.ErrSyntax
+	}
+	return RateLimit{Requests: requests, Per: per}, nil
+}
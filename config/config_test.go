@@ -0,0 +1,141 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	for _, testCase := range []struct {
+		raw      string
+		expected RateLimit
+		ok       bool
+	}{
+		{"60/1m", RateLimit{60, time.Minute}, true},
+		{"5/10s", RateLimit{5, 10 * time.Second}, true},
+		{"off", RateLimit{}, true},
+		{"60", RateLimit{}, false},
+		{"lots/1m", RateLimit{}, false},
+		{"60/soon", RateLimit{}, false},
+		{"60/0s", RateLimit{}, false},
+	} {
+		limit, err := ParseRateLimit(testCase.raw)
+		assert.Equal(t, testCase.ok, err == nil, "CASE: %s", testCase.raw)
+		assert.Equal(t, testCase.expected, limit, "CASE: %s", testCase.raw)
+	}
+}
+
+// Returns a lookup function for a fake environment.
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "zbbapi-config-*.yaml")
+	assert.Nil(t, err)
+	defer file.Close()
+	_, err = file.WriteString(content)
+	assert.Nil(t, err)
+	return file.Name()
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := load(nil, fakeEnv(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, defaults(), c)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+listen-address: ":9000"
+read-timeout: 5s
+database-prefix: file_
+rate-limit-write: 10/1s
+`)
+	defer os.Remove(path)
+
+	c, err := load([]string{"-config", path, "-read-timeout", "7s"}, fakeEnv(map[string]string{
+		"DATABASE_PREFIX": "env_",
+		"READ_TIMEOUT":    "6s",
+		"LOG_LEVEL":       "",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, ":9000", c.ListenAddress, "the file should override the defaults")
+	assert.Equal(t, "env_", c.DatabasePrefix, "the environment should override the file")
+	assert.Equal(t, 7*time.Second, c.ReadTimeout, "flags should override everything")
+	assert.Equal(t, RateLimit{10, time.Second}, c.RateLimits["write"])
+	assert.Equal(t, RateLimit{300, time.Minute}, c.RateLimits["read"])
+	assert.Equal(t, "info", c.LogLevel, "an empty environment variable should be ignored")
+}
+
+func TestLoadFindsFileInEnvironment(t *testing.T) {
+	path := writeConfigFile(t, "log-level: debug\n")
+	defer os.Remove(path)
+
+	c, err := load(nil, fakeEnv(map[string]string{"CONFIG_FILE": path}))
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", c.LogLevel)
+}
+
+func TestLoadReportsEveryInvalidSetting(t *testing.T) {
+	path := writeConfigFile(t, "listen-adress: \":9000\"\nwrite-timeout: 30\n")
+	defer os.Remove(path)
+
+	_, err := load([]string{"-config", path, "-tls-cert-file", "cert.pem"}, fakeEnv(map[string]string{
+		"TRASH_SWEEP_INTERVAL": "0s",
+		"RATE_LIMIT_AUTH":      "lots",
+		"RATE_LIMIT_STORE":     "redis",
+		"LOG_LEVEL":            "loud",
+		"TENANT_HEADER":        "X-Tenant-ID",
+	}))
+	invalid, ok := err.(InvalidError)
+	assert.True(t, ok)
+	assert.Len(t, invalid, 8)
+	for _, expected := range []string{
+		"listen-adress (from " + path + "): there's no such setting",
+		"write-timeout (from " + path + ")",
+		"trash-sweep-interval (from the environment)",
+		"rate-limit-auth (from the environment)",
+		"tls-cert-file and tls-key-file",
+		"rate-limit-store",
+		"log-level",
+		"proxy-secret",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+}
+
+func TestLoadRejectsNestedFileSettings(t *testing.T) {
+	path := writeConfigFile(t, "rate-limits:\n  read: 1/1s\n")
+	defer os.Remove(path)
+
+	_, err := load([]string{"-config", path}, fakeEnv(nil))
+	assert.NotNil(t, err)
+}
+
+func TestLoadAllowsTimeoutsToBeTurnedOff(t *testing.T) {
+	c, err := load([]string{"-idle-timeout", "0s"}, fakeEnv(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), c.IdleTimeout)
+
+	_, err = load([]string{"-shutdown-timeout", "0s"}, fakeEnv(nil))
+	assert.NotNil(t, err, "only timeouts that limit clients can be turned off")
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "LISTEN_ADDRESS", envName("listen-address"))
+	assert.Equal(t, "MONGO_URL", envName("mongo-url"))
+}
+
+func TestExampleFileMatchesDefaults(t *testing.T) {
+	c, err := load([]string{"-config", "../config.example.yaml"}, fakeEnv(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, defaults(), c)
+}
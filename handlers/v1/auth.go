@@ -0,0 +1,260 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/households"
+	"github.com/hjkelly/zbbapi/services/tokens"
+	"github.com/hjkelly/zbbapi/services/users"
+	"github.com/julienschmidt/httprouter"
+)
+
+// publicRoutes can be used without logging in, keyed by method and path. Every other route requires a token.
+var publicRoutes = map[string]bool{
+	"POST /v1/users":       true,
+	"POST /v1/sessions":    true,
+	"GET /v1/openapi.json": true,
+	"GET /healthz":         true,
+	"GET /readyz":          true,
+}
+
+// accountRoutes act on the caller's own account or households, rather than the data in their current household, so they check permissions themselves.
+var accountRoutes = map[string]bool{
+	"GET /v1/users/me":                                         true,
+	"GET /v1/households":                                       true,
+	"POST /v1/households":                                      true,
+	"GET /v1/households/:id":                                   true,
+	"POST /v1/households/:id/invitations":                      true,
+	"DELETE /v1/households/:id/invitations/:invitationId":      true,
+	"POST /v1/households/:id/invitations/:invitationId/accept": true,
+	"PUT /v1/households/:id/members/:userId":                   true,
+	"DELETE /v1/households/:id/members/:userId":                true,
+	"GET /v1/invitations":                                      true,
+	"GET /v1/tokens":                                           true,
+	"POST /v1/tokens":                                          true,
+	"DELETE /v1/tokens/:id":                                    true,
+}
+
+// ownerRoutes are the account routes only a household's owners may use.
+var ownerRoutes = map[string]bool{
+	"POST /v1/households/:id/invitations":                 true,
+	"DELETE /v1/households/:id/invitations/:invitationId": true,
+	"PUT /v1/households/:id/members/:userId":              true,
+	"DELETE /v1/households/:id/members/:userId":           true,
+}
+
+// proxySecretHeader carries the secret our proxy sends along with the tenant header, so a client can't set the tenant header themselves.
+const proxySecretHeader = "X-Proxy-Secret"
+
+// householdHeader lets members choose which household's data a request works with. Without it, they work with their own personal data, which they own.
+const householdHeader = "X-Household-ID"
+
+// authenticated only lets the request through if it has a valid token in its Authorization header, and lets the handler know who made it. The token can be one they got by logging in, or an access token limited to some scopes.
+func authenticated(handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		token := bearerToken(r.Header.Get("Authorization"))
+		if token == "" {
+			writeUnauthorized(w, common.UnauthorizedErr)
+			return
+		}
+		caller, err := identify(common.LoggerFor(r.Context()), token)
+		if err != nil {
+			writeUnauthorized(w, err)
+			return
+		}
+		caller.TenantID, caller.Role, err = membershipFor(r, caller.UserID)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		caller.RequestID = common.GetRequestID(r.Context())
+		caller.Locale = common.GetLocale(r.Context())
+		r = r.WithContext(common.WithCaller(r.Context(), caller))
+		handle(w, r, params)
+	}
+}
+
+// Works out who a token belongs to, and what it limits them to.
+func identify(logger common.Logger, token string) (common.Caller, error) {
+	if strings.HasPrefix(token, models.AccessTokenPrefix) {
+		accessToken, err := tokens.Authenticate(logger, token)
+		if err != nil {
+			return common.Caller{}, err
+		}
+		// Nil scopes would mean full access, so a token without any gets none.
+		scopes := append([]string{}, accessToken.Scopes...)
+		return common.Caller{UserID: string(accessToken.UserID), Scopes: scopes}, nil
+	}
+	user, err := users.Authenticate(token)
+	if err != nil {
+		return common.Caller{}, err
+	}
+	return common.Caller{UserID: string(user.ID)}, nil
+}
+
+// Decides whose data an authenticated request works with, and the user's role there. If a tenant header is configured, the request must come through the proxy that sets it, which vouches for the user, so they're treated as an owner. Otherwise it's the household they asked for, if they belong to it, or their own personal data.
+func membershipFor(r *http.Request, userID string) (string, string, error) {
+	cfg := config.GetConfig()
+	if header := cfg.TenantHeader; header != "" {
+		if !fromProxy(r, cfg.ProxySecret) {
+			return "", "", common.ForbiddenErr
+		}
+		tenant := strings.TrimSpace(r.Header.Get(header))
+		if tenant == "" {
+			return "", "", common.MissingTenantErr
+		}
+		return tenant, models.RoleOwner, nil
+	}
+	householdID := strings.TrimSpace(r.Header.Get(householdHeader))
+	if householdID == "" {
+		return userID, models.RoleOwner, nil
+	}
+	role, err := households.RoleOf(householdID, userID)
+	if err != nil {
+		return "", "", err
+	}
+	return householdID, role, nil
+}
+
+// Checks that the request came through our proxy, which sends the configured secret. Without a secret, nothing can prove it did.
+func fromProxy(r *http.Request, secret string) bool {
+	sent := r.Header.Get(proxySecretHeader)
+	return secret != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(secret)) == 1
+}
+
+// requiredRole says what role a route needs in the caller's household: any role can read, but only editors and owners can make changes. Public and account routes don't need one.
+func requiredRole(method, path string) string {
+	key := method + " " + path
+	if publicRoutes[key] || accountRoutes[key] {
+		return ""
+	}
+	if method == "GET" {
+		return models.RoleViewer
+	}
+	return models.RoleEditor
+}
+
+// tokenScopes says which scopes an access token needs to use a route, and whether access tokens can use it at all. Account routes need someone who logged in. Batches don't need a scope of their own, since each operation is checked as it runs.
+func tokenScopes(method, path string, params httprouter.Params) ([]string, bool) {
+	if accountRoutes[method+" "+path] {
+		return nil, false
+	}
+	access := ":write"
+	if method == "GET" {
+		access = ":read"
+	}
+	segments := strings.Split(strings.TrimPrefix(path, "/v1/"), "/")
+	switch segments[0] {
+	case "batch":
+		return nil, true
+	case "trash":
+		if method == "GET" {
+			scopes := make([]string, 0, len(models.ScopedResources))
+			for _, resource := range models.ScopedResources {
+				scopes = append(scopes, resource+access)
+			}
+			return scopes, true
+		}
+		return []string{params.ByName("resource") + access}, true
+	default:
+		return []string{segments[0] + access}, true
+	}
+}
+
+// withinScope only lets the request through if the caller's access token (if they used one) has the scopes the route needs.
+func withinScope(method, path string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		caller := callerOf(r)
+		if caller.Scopes != nil {
+			scopes, allowed := tokenScopes(method, path, params)
+			for _, scope := range scopes {
+				allowed = allowed && caller.HasScope(scope)
+			}
+			if !allowed {
+				common.WriteErrorResponse(w, common.InsufficientScopeErr)
+				return
+			}
+		}
+		handle(w, r, params)
+	}
+}
+
+// permitted only lets the request through if the caller's role allows it.
+func permitted(required string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if !models.RoleAllows(callerOf(r).Role, required) {
+			common.WriteErrorResponse(w, common.ForbiddenErr)
+			return
+		}
+		handle(w, r, params)
+	}
+}
+
+// callerOf returns who made an authenticated request, and which tenant they're working with.
+func callerOf(r *http.Request) common.Caller {
+	caller, _ := common.GetCaller(r.Context())
+	return caller
+}
+
+// Finds the token in an Authorization header like "Bearer <token>".
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// Tells the client how to authenticate, as HTTP requires for a 401.
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	if err == common.UnauthorizedErr {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="zbbapi"`)
+	}
+	common.WriteErrorResponse(w, err)
+}
+
+func register(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// Parse the request body.
+	var creds models.Credentials
+	err := common.DecodeStrict(r.Body, &creds)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := users.Register(creds)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 201, result)
+}
+
+func login(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// Parse the request body.
+	var creds models.Credentials
+	err := common.DecodeStrict(r.Body, &creds)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := users.Login(creds)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 201, result)
+}
+
+func retrieveCurrentUser(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	result, err := users.Retrieve(callerOf(r).UserID)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc.def.ghi", bearerToken("Bearer abc.def.ghi"))
+	assert.Equal(t, "abc", bearerToken("bearer  abc "))
+	assert.Equal(t, "", bearerToken("Basic abc"))
+	assert.Equal(t, "", bearerToken("Bearer"))
+	assert.Equal(t, "", bearerToken(""))
+}
+
+func TestAuthenticatedRejectsMissingOrBadTokens(t *testing.T) {
+	called := false
+	handle := authenticated(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		called = true
+	})
+	for _, header := range []string{"", "Bearer not-a-token"} {
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/v1/plans", nil)
+		r.Header.Set("Authorization", header)
+		handle(recorder, r, nil)
+		assert.Equal(t, 401, recorder.Code, "CASE: %q", header)
+		assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"), "CASE: %q", header)
+	}
+	assert.False(t, called)
+}
+
+func TestMembershipFor(t *testing.T) {
+	cfg := config.GetConfig()
+	originalHeader, originalSecret := cfg.TenantHeader, cfg.ProxySecret
+	defer func() { cfg.TenantHeader, cfg.ProxySecret = originalHeader, originalSecret }()
+	cfg.ProxySecret = "shh"
+
+	r := httptest.NewRequest("GET", "/v1/plans", nil)
+	r.Header.Set("X-Tenant-ID", "household-1")
+	r.Header.Set("X-Proxy-Secret", "shh")
+
+	cfg.TenantHeader = ""
+	tenant, role, err := membershipFor(r, "user-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", tenant, "without a household, each user should work with their own data")
+	assert.Equal(t, models.RoleOwner, role)
+
+	cfg.TenantHeader = "X-Tenant-ID"
+	tenant, role, err = membershipFor(r, "user-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "household-1", tenant)
+	assert.Equal(t, models.RoleOwner, role)
+
+	missing := httptest.NewRequest("GET", "/v1/plans", nil)
+	missing.Header.Set("X-Proxy-Secret", "shh")
+	_, _, err = membershipFor(missing, "user-1")
+	assert.Equal(t, common.MissingTenantErr, err)
+}
+
+func TestMembershipForOnlyTrustsTheProxy(t *testing.T) {
+	cfg := config.GetConfig()
+	originalHeader, originalSecret := cfg.TenantHeader, cfg.ProxySecret
+	defer func() { cfg.TenantHeader, cfg.ProxySecret = originalHeader, originalSecret }()
+	cfg.TenantHeader = "X-Tenant-ID"
+
+	for _, testCase := range []struct {
+		name       string
+		configured string
+		sent       string
+	}{
+		{"no secret sent", "shh", ""},
+		{"wrong secret", "shh", "guess"},
+		{"no secret configured", "", ""},
+	} {
+		cfg.ProxySecret = testCase.configured
+		r := httptest.NewRequest("GET", "/v1/plans", nil)
+		r.Header.Set("X-Tenant-ID", "someone-elses-household")
+		r.Header.Set("X-Proxy-Secret", testCase.sent)
+		_, _, err := membershipFor(r, "user-1")
+		assert.Equal(t, common.ForbiddenErr, err, "CASE: %s", testCase.name)
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	for _, testCase := range []struct {
+		method, path string
+		expected     string
+	}{
+		{"GET", "/v1/plans", models.RoleViewer},
+		{"GET", "/v1/budgets/:id/totals", models.RoleViewer},
+		{"POST", "/v1/plans", models.RoleEditor},
+		{"PUT", "/v1/budgets/:id", models.RoleEditor},
+		{"DELETE", "/v1/categories/:id", models.RoleEditor},
+		{"POST", "/v1/trash/:resource/:id/restore", models.RoleEditor},
+		{"POST", "/v1/sessions", ""},
+		{"POST", "/v1/households", ""},
+		{"GET", "/v1/users/me", ""},
+	} {
+		assert.Equal(t, testCase.expected, requiredRole(testCase.method, testCase.path), "CASE: %s %s", testCase.method, testCase.path)
+	}
+}
+
+func TestPermitted(t *testing.T) {
+	for _, testCase := range []struct {
+		role     string
+		required string
+		allowed  bool
+	}{
+		{models.RoleViewer, models.RoleViewer, true},
+		{models.RoleViewer, models.RoleEditor, false},
+		{models.RoleEditor, models.RoleEditor, true},
+		{models.RoleOwner, models.RoleEditor, true},
+		{"", models.RoleViewer, false},
+	} {
+		called := false
+		handle := permitted(testCase.required, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			called = true
+		})
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/v1/plans", nil)
+		r = r.WithContext(common.WithCaller(r.Context(), common.Caller{UserID: "u1", TenantID: "h1", Role: testCase.role}))
+		handle(recorder, r, nil)
+		assert.Equal(t, testCase.allowed, called, "CASE: %q needing %q", testCase.role, testCase.required)
+		if !testCase.allowed {
+			assert.Equal(t, 403, recorder.Code, "CASE: %q needing %q", testCase.role, testCase.required)
+		}
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	for _, testCase := range []struct {
+		method, path string
+		params       httprouter.Params
+		scopes       []string
+		allowed      bool
+	}{
+		{"GET", "/v1/plans", nil, []string{"plans:read"}, true},
+		{"PUT", "/v1/budgets/:id", nil, []string{"budgets:write"}, true},
+		{"POST", "/v1/categories/:id/revisions/:version/revert", nil, []string{"categories:write"}, true},
+		{"GET", "/v1/trash", nil, []string{"categories:read", "plans:read", "budgets:read"}, true},
+		{"POST", "/v1/trash/:resource/:id/restore", httprouter.Params{{Key: "resource", Value: "plans"}}, []string{"plans:write"}, true},
+		{"POST", "/v1/batch", nil, nil, true},
+		{"POST", "/v1/tokens", nil, nil, false},
+		{"GET", "/v1/users/me", nil, nil, false},
+	} {
+		scopes, allowed := tokenScopes(testCase.method, testCase.path, testCase.params)
+		assert.Equal(t, testCase.scopes, scopes, "CASE: %s %s", testCase.method, testCase.path)
+		assert.Equal(t, testCase.allowed, allowed, "CASE: %s %s", testCase.method, testCase.path)
+	}
+}
+
+func TestWithinScope(t *testing.T) {
+	for _, testCase := range []struct {
+		method, path string
+		scopes       []string
+		allowed      bool
+	}{
+		{"GET", "/v1/plans", nil, true},
+		{"GET", "/v1/plans", []string{"plans:read"}, true},
+		{"POST", "/v1/plans", []string{"plans:read"}, false},
+		{"POST", "/v1/plans", []string{"plans:write"}, true},
+		{"GET", "/v1/budgets", []string{"plans:write"}, false},
+		{"POST", "/v1/tokens", []string{"plans:write"}, false},
+		{"POST", "/v1/tokens", nil, true},
+	} {
+		called := false
+		handle := withinScope(testCase.method, testCase.path, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			called = true
+		})
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest(testCase.method, testCase.path, nil)
+		r = r.WithContext(common.WithCaller(r.Context(), common.Caller{UserID: "u1", Scopes: testCase.scopes}))
+		handle(recorder, r, nil)
+		assert.Equal(t, testCase.allowed, called, "CASE: %v for %s %s", testCase.scopes, testCase.method, testCase.path)
+		if !testCase.allowed {
+			assert.Equal(t, 403, recorder.Code, "CASE: %v for %s %s", testCase.scopes, testCase.method, testCase.path)
+		}
+	}
+}
+
+func TestPublicRoutesExist(t *testing.T) {
+	registered := map[string]bool{}
+	for _, r := range routes() {
+		registered[r.Method+" "+r.Path] = true
+	}
+	for key := range publicRoutes {
+		assert.True(t, registered[key], "public route %s isn't registered", key)
+	}
+	for key := range accountRoutes {
+		assert.True(t, registered[key], "account route %s isn't registered", key)
+	}
+	for key := range ownerRoutes {
+		assert.True(t, accountRoutes[key], "owner route %s isn't an account route", key)
+	}
+}
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/batch"
+	"github.com/julienschmidt/httprouter"
+)
+
+func runBatch(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// Parse the request body.
+	var req models.BatchRequest
+	err := common.DecodeStrict(r.Body, &req)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Run it.
+	result, err := batch.Run(callerOf(r), req)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
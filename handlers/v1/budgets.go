@@ -1,7 +1,7 @@
 package v1
 
 import (
-	"encoding/json"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/hjkelly/zbbapi/common"
@@ -11,24 +11,50 @@ import (
 )
 
 func listBudgets(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	results, err := budgets.List()
+	results, err := budgets.List(callerOf(r))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
 	}
+	if common.WantsExpansion(r, "category") {
+		expanding := make([]*models.Budget, 0, len(results))
+		for idx := range results {
+			expanding = append(expanding, &results[idx])
+		}
+		err = budgets.ExpandCategories(callerOf(r), expanding...)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+	}
 	common.WriteResponse(w, 200, results)
 }
 
 func createBudget(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Parse the request body.
 	var budget models.Budget
-	err := json.NewDecoder(r.Body).Decode(&budget)
+	err = common.DecodeStrict(r.Body, &budget)
 	if err != nil {
-		common.WriteErrorResponse(w, common.ParseErr)
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Just show what would be saved, if that's all they want.
+	if dryRun {
+		result, err := budgets.Preview(callerOf(r), budget)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
 		return
 	}
 	// Save it.
-	result, err := budgets.Create(budget)
+	result, err := budgets.Create(callerOf(r), budget)
 	if err != nil {
 		// TODO: Handle validation vs. DB error...
 		common.WriteErrorResponse(w, err)
@@ -38,7 +64,23 @@ func createBudget(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 }
 
 func retrieveBudget(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	result, err := budgets.Retrieve(params.ByName("id"))
+	result, err := budgets.Retrieve(callerOf(r), params.ByName("id"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	if common.WantsExpansion(r, "category") {
+		err = budgets.ExpandCategories(callerOf(r), result)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func retrieveBudgetTotals(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	result, err := budgets.Totals(callerOf(r), params.ByName("id"))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -47,15 +89,71 @@ func retrieveBudget(w http.ResponseWriter, r *http.Request, params httprouter.Pa
 }
 
 func updateBudget(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Parse the request body.
 	var budget models.Budget
-	err := json.NewDecoder(r.Body).Decode(&budget)
+	err = common.DecodeStrict(r.Body, &budget)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Update according to the URL, or just show what the update would be.
+	update := budgets.UpdateID
+	if dryRun {
+		update = budgets.PreviewUpdateID
+	}
+	result, err := update(callerOf(r), params.ByName("id"), budget, expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func patchBudget(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Read the merge patch; it gets applied to the stored Budget before validation.
+	err = common.CheckMergePatchType(r.Header.Get("Content-Type"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	patch, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		common.WriteErrorResponse(w, common.ParseErr)
 		return
 	}
-	// Update according to the URL.
-	result, err := budgets.UpdateID(params.ByName("id"), budget)
+	err = common.CheckStrict(patch, models.Budget{})
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Update according to the URL, or just show what the update would be.
+	patchID := budgets.PatchID
+	if dryRun {
+		patchID = budgets.PreviewPatchID
+	}
+	result, err := patchID(callerOf(r), params.ByName("id"), patch, expectedVersion)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -64,7 +162,13 @@ func updateBudget(w http.ResponseWriter, r *http.Request, params httprouter.Para
 }
 
 func deleteBudget(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	err := budgets.Delete(params.ByName("id"))
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	err = budgets.Delete(callerOf(r), params.ByName("id"), expectedVersion)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
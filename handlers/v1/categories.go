@@ -1,8 +1,7 @@
 package v1
 
 import (
-	"encoding/json"
-	"log"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/hjkelly/zbbapi/common"
@@ -12,7 +11,16 @@ import (
 )
 
 func listCategories(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	results, err := categories.List()
+	if r.URL.Query().Get("tree") == "true" {
+		tree, err := categories.Tree(callerOf(r))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, tree)
+		return
+	}
+	results, err := categories.List(callerOf(r))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -23,14 +31,13 @@ func listCategories(w http.ResponseWriter, r *http.Request, _ httprouter.Params)
 func createCategory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	// Parse the request body.
 	var category models.Category
-	err := json.NewDecoder(r.Body).Decode(&category)
+	err := common.DecodeStrict(r.Body, &category)
 	if err != nil {
-		log.Print(err.Error())
-		common.WriteErrorResponse(w, common.ParseErr)
+		common.WriteErrorResponse(w, err)
 		return
 	}
 	// Save it.
-	result, err := categories.Create(category)
+	result, err := categories.Create(callerOf(r), category)
 	if err != nil {
 		// TODO: Handle validation vs. DB error...
 		common.WriteErrorResponse(w, err)
@@ -40,7 +47,7 @@ func createCategory(w http.ResponseWriter, r *http.Request, _ httprouter.Params)
 }
 
 func retrieveCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	result, err := categories.Retrieve(params.ByName("id"))
+	result, err := categories.Retrieve(callerOf(r), params.ByName("id"))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -49,16 +56,53 @@ func retrieveCategory(w http.ResponseWriter, r *http.Request, params httprouter.
 }
 
 func updateCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Parse the request body.
 	var category models.Category
-	err := json.NewDecoder(r.Body).Decode(&category)
+	err = common.DecodeStrict(r.Body, &category)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Update according to the URL.
+	result, err := categories.UpdateID(callerOf(r), params.ByName("id"), category, expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func patchCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Read the merge patch; it gets applied to the stored Category before validation.
+	err = common.CheckMergePatchType(r.Header.Get("Content-Type"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	patch, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		log.Print(err.Error())
 		common.WriteErrorResponse(w, common.ParseErr)
 		return
 	}
+	err = common.CheckStrict(patch, models.Category{})
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Update according to the URL.
-	result, err := categories.UpdateID(params.ByName("id"), category)
+	result, err := categories.PatchID(callerOf(r), params.ByName("id"), patch, expectedVersion)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -67,7 +111,14 @@ func updateCategory(w http.ResponseWriter, r *http.Request, params httprouter.Pa
 }
 
 func deleteCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	err := categories.Delete(params.ByName("id"))
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// If plans or budgets still use this category, they can be moved to another one in the same step.
+	err = categories.Delete(callerOf(r), params.ByName("id"), expectedVersion, r.URL.Query().Get("reassignTo"))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
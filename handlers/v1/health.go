@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/health"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Tells an orchestrator the process is running, so it isn't restarted.
+func checkLiveness(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	writeHealth(w, health.Alive())
+}
+
+// Tells an orchestrator whether we can handle requests yet, i.e. whether we can reach Mongo and have set it up.
+func checkReadiness(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	writeHealth(w, health.Ready())
+}
+
+func writeHealth(w http.ResponseWriter, report models.Health) {
+	w.Header().Set("Cache-Control", "no-store")
+	status := http.StatusOK
+	if report.Status != models.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	common.WriteResponse(w, status, report)
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/households"
+	"github.com/julienschmidt/httprouter"
+)
+
+func listHouseholds(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	results, err := households.List(callerOf(r))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, results)
+}
+
+func createHousehold(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// Parse the request body.
+	var household models.Household
+	err := common.DecodeStrict(r.Body, &household)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := households.Create(callerOf(r), household)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 201, result)
+}
+
+func retrieveHousehold(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	result, err := households.Retrieve(callerOf(r), params.ByName("id"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func inviteToHousehold(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Parse the request body.
+	var invitation models.Invitation
+	err := common.DecodeStrict(r.Body, &invitation)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := households.Invite(callerOf(r), params.ByName("id"), invitation)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 201, result)
+}
+
+func revokeInvitation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	err = households.RevokeInvitation(callerOf(r), params.ByName("id"), params.ByName("invitationId"), expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 204, nil)
+}
+
+func acceptInvitation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	result, err := households.Accept(callerOf(r), params.ByName("id"), params.ByName("invitationId"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func listInvitations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	results, err := households.ListInvitations(callerOf(r))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, results)
+}
+
+func changeMemberRole(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Parse the request body.
+	var change models.RoleChange
+	err = common.DecodeStrict(r.Body, &change)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := households.ChangeRole(callerOf(r), params.ByName("id"), params.ByName("userId"), change, expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func removeMember(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	err = households.RemoveMember(callerOf(r), params.ByName("id"), params.ByName("userId"), expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 204, nil)
+}
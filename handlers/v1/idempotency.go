@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/services/idempotency"
+	"github.com/julienschmidt/httprouter"
+)
+
+// idempotencyStore is where idempotent keeps keys and the responses to replay for them.
+var idempotencyStore idempotency.Store = idempotency.MongoStore{}
+
+// idempotent lets clients safely retry a request by sending an Idempotency-Key header. The first response for a key is stored and replayed for any retry of the same request, rather than handling it again.
+func idempotent(handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// A dry run doesn't create anything, so there's nothing to protect, and its preview mustn't be replayed for the real request.
+		key := r.Header.Get("Idempotency-Key")
+		dryRun, _ := common.WantsDryRun(r)
+		if key == "" || dryRun {
+			handle(w, r, params)
+			return
+		}
+		// Keys only need to be unique per user, and one user mustn't be able to replay another's response. The same user's requests in different households are different requests, too.
+		if caller, ok := common.GetCaller(r.Context()); ok {
+			key = caller.TenantID + "/" + caller.UserID + "/" + key
+		}
+
+		// We need the body to tell retries apart from a different request, so read it and put it back for the handler.
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			common.WriteErrorResponse(w, common.ParseErr)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		stored, err := idempotencyStore.Begin(key, idempotency.Fingerprint(r.Method, r.URL.Path, body))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		if stored != nil {
+			replayResponse(w, stored)
+			return
+		}
+
+		// If the handler blows up, don't leave the key stuck "in progress" until it expires.
+		defer func() {
+			if p := recover(); p != nil {
+				idempotencyStore.Release(key)
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, status: 200}
+		handle(recorder, r, params)
+
+		// Don't hold the client to our own failures; let them retry for real.
+		if recorder.status >= 500 {
+			err = idempotencyStore.Release(key)
+		} else {
+			err = idempotencyStore.Finish(key, recorder.status, recorder.Header().Get("ETag"), recorder.body.Bytes())
+		}
+		if err != nil {
+			common.LoggerFor(r.Context()).Error("Couldn't record the response for Idempotency-Key %s: %s", key, err.Error())
+		}
+	}
+}
+
+// Writes a stored response as if we'd just handled the request again.
+func replayResponse(w http.ResponseWriter, stored *idempotency.Record) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Idempotent-Replayed", "true")
+	if stored.ETag != "" {
+		w.Header().Set("ETag", stored.ETag)
+	}
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// responseRecorder passes a response through to the client while keeping a copy of its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
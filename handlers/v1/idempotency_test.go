@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/services/idempotency"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIdempotencyStore keeps keys in a map, the way MongoStore keeps them in Mongo.
+type fakeIdempotencyStore map[string]*idempotency.Record
+
+func (store fakeIdempotencyStore) Begin(key, fingerprint string) (*idempotency.Record, error) {
+	existing, ok := store[key]
+	if !ok {
+		store[key] = &idempotency.Record{Key: key, Fingerprint: fingerprint}
+		return nil, nil
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, common.IdempotencyKeyReusedErr
+	}
+	if !existing.Completed {
+		return nil, common.IdempotencyKeyInProgressErr
+	}
+	return existing, nil
+}
+
+func (store fakeIdempotencyStore) Finish(key string, status int, etag string, body []byte) error {
+	record := store[key]
+	record.Completed, record.Status, record.ETag, record.Body = true, status, etag, body
+	return nil
+}
+
+func (store fakeIdempotencyStore) Release(key string) error {
+	delete(store, key)
+	return nil
+}
+
+// countingCreate stands in for a create handler, numbering each thing it creates.
+type countingCreate struct {
+	created int
+}
+
+func (handler *countingCreate) handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if dryRun, _ := common.WantsDryRun(r); dryRun {
+		common.WriteResponse(w, 200, map[string]int{"number": handler.created + 1})
+		return
+	}
+	handler.created++
+	w.Header().Set("ETag", `"1"`)
+	common.WriteResponse(w, 201, map[string]int{"number": handler.created})
+}
+
+// Sends a create request with an Idempotency-Key on behalf of the user, in the household.
+func sendIdempotent(handle httprouter.Handle, target, tenantID, userID, key string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", target, strings.NewReader(`{"name":"Groceries"}`))
+	r.Header.Set("Idempotency-Key", key)
+	r = r.WithContext(common.WithCaller(r.Context(), common.Caller{TenantID: tenantID, UserID: userID}))
+	recorder := httptest.NewRecorder()
+	handle(recorder, r, nil)
+	return recorder
+}
+
+func TestIdempotentReplays(t *testing.T) {
+	original := idempotencyStore
+	defer func() { idempotencyStore = original }()
+	idempotencyStore = fakeIdempotencyStore{}
+
+	handler := &countingCreate{}
+	handle := idempotent(handler.handle)
+	first := sendIdempotent(handle, "/v1/categories", "home", "me", "abc")
+	second := sendIdempotent(handle, "/v1/categories", "home", "me", "abc")
+
+	assert.Equal(t, 1, handler.created)
+	assert.Equal(t, 201, second.Code)
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, `"1"`, second.Header().Get("ETag"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+}
+
+func TestIdempotentKeysAreScopedToTheHousehold(t *testing.T) {
+	original := idempotencyStore
+	defer func() { idempotencyStore = original }()
+	idempotencyStore = fakeIdempotencyStore{}
+
+	handler := &countingCreate{}
+	handle := idempotent(handler.handle)
+	for idx, tenantID := range []string{"home", "cabin"} {
+		recorder := sendIdempotent(handle, "/v1/categories", tenantID, "me", "abc")
+		assert.Equal(t, "", recorder.Header().Get("Idempotent-Replayed"), "CASE: %s", tenantID)
+		assert.Contains(t, recorder.Body.String(), strconv.Itoa(idx+1), "CASE: %s", tenantID)
+	}
+	assert.Equal(t, 2, handler.created)
+}
+
+func TestIdempotentSkipsDryRuns(t *testing.T) {
+	original := idempotencyStore
+	defer func() { idempotencyStore = original }()
+	idempotencyStore = fakeIdempotencyStore{}
+
+	handler := &countingCreate{}
+	handle := idempotent(handler.handle)
+	preview := sendIdempotent(handle, "/v1/plans?dryRun=true", "home", "me", "abc")
+	assert.Equal(t, 200, preview.Code)
+	assert.Equal(t, 0, handler.created)
+
+	created := sendIdempotent(handle, "/v1/plans", "home", "me", "abc")
+	assert.Equal(t, 201, created.Code, "the real request shouldn't get the dry run's response")
+	assert.Equal(t, "", created.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, handler.created)
+}
@@ -1,24 +1,97 @@
 package v1
 
-import "github.com/julienschmidt/httprouter"
-
-// RegisterHandlers links all current route handlers to the router provided.
-func RegisterHandlers(router *httprouter.Router) {
-	router.GET("/v1/categories", listCategories)
-	router.POST("/v1/categories", createCategory)
-	router.GET("/v1/categories/:id", retrieveCategory)
-	router.PUT("/v1/categories/:id", updateCategory)
-	router.DELETE("/v1/categories/:id", deleteCategory)
-
-	router.GET("/v1/plans", listPlans)
-	router.POST("/v1/plans", createPlan)
-	router.GET("/v1/plans/:id", retrievePlan)
-	router.PUT("/v1/plans/:id", updatePlan)
-	router.DELETE("/v1/plans/:id", deletePlan)
-
-	router.GET("/v1/budgets", listBudgets)
-	router.POST("/v1/budgets", createBudget)
-	router.GET("/v1/budgets/:id", retrieveBudget)
-	router.PUT("/v1/budgets/:id", updateBudget)
-	router.DELETE("/v1/budgets/:id", deleteBudget)
+import (
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Router is anything we can link route handlers to, like *httprouter.Router.
+type Router interface {
+	Handle(method, path string, handle httprouter.Handle)
+}
+
+// route describes a single endpoint: where it lives, what handles it, and (for the OpenAPI spec) what it accepts and returns. Request and Response hold a zero value of the body's model, or nil if there's no body.
+type route struct {
+	Method   string
+	Path     string
+	Handle   httprouter.Handle
+	Summary  string
+	Request  interface{}
+	Status   int
+	Response interface{}
+}
+
+// RegisterHandlers links all current route handlers to the router provided. Routes that aren't public require the client to log in (or use an access token with the right scopes) first, with a role that allows what the route does.
+func RegisterHandlers(router Router) {
+	for _, r := range routes() {
+		handle := r.Handle
+		if required := requiredRole(r.Method, r.Path); required != "" {
+			handle = permitted(required, handle)
+		}
+		if !publicRoutes[r.Method+" "+r.Path] {
+			handle = authenticated(withinScope(r.Method, r.Path, handle))
+		}
+		router.Handle(r.Method, r.Path, routed(r.Path, handle))
+	}
+}
+
+// routes lists every endpoint in v1. Anything added here is automatically documented in the OpenAPI spec.
+func routes() []route {
+	all := []route{
+		{"GET", "/healthz", checkLiveness, "Check that the server is running", nil, 200, models.Health{}},
+		{"GET", "/readyz", checkReadiness, "Check that the server can reach its database and is ready for requests", nil, 200, models.Health{}},
+
+		{"POST", "/v1/users", register, "Register a user", models.Credentials{}, 201, models.User{}},
+		{"GET", "/v1/users/me", retrieveCurrentUser, "Retrieve the user who's logged in", nil, 200, models.User{}},
+		{"POST", "/v1/sessions", login, "Log in, getting a token to send in the Authorization header", models.Credentials{}, 201, models.Session{}},
+
+		{"GET", "/v1/tokens", listAccessTokens, "List your access tokens", nil, 200, []models.AccessToken{}},
+		{"POST", "/v1/tokens", createAccessToken, "Create an access token for a script or integration; the token is only shown this once", models.AccessToken{}, 201, models.NewAccessToken{}},
+		{"DELETE", "/v1/tokens/:id", revokeAccessToken, "Revoke an access token", nil, 204, nil},
+
+		{"GET", "/v1/households", listHouseholds, "List the households you belong to", nil, 200, []models.Household{}},
+		{"POST", "/v1/households", createHousehold, "Create a household, which you'll own", models.Household{}, 201, models.Household{}},
+		{"GET", "/v1/households/:id", retrieveHousehold, "Retrieve a household you belong to", nil, 200, models.Household{}},
+		{"POST", "/v1/households/:id/invitations", inviteToHousehold, "Invite someone to a household you own", models.Invitation{}, 201, models.Invitation{}},
+		{"DELETE", "/v1/households/:id/invitations/:invitationId", revokeInvitation, "Revoke an invitation to a household you own", nil, 204, nil},
+		{"POST", "/v1/households/:id/invitations/:invitationId/accept", acceptInvitation, "Accept an invitation, joining the household", nil, 200, models.Household{}},
+		{"PUT", "/v1/households/:id/members/:userId", changeMemberRole, "Change a member's role in a household you own", models.RoleChange{}, 200, models.Household{}},
+		{"DELETE", "/v1/households/:id/members/:userId", removeMember, "Remove a member from a household you own, or leave one", nil, 204, nil},
+		{"GET", "/v1/invitations", listInvitations, "List invitations waiting for you to accept them", nil, 200, []models.PendingInvitation{}},
+
+		{"GET", "/v1/categories", listCategories, "List categories", nil, 200, []models.Category{}},
+		{"POST", "/v1/categories", idempotent(createCategory), "Create a category", models.Category{}, 201, models.Category{}},
+		{"GET", "/v1/categories/:id", retrieveCategory, "Retrieve a category", nil, 200, models.Category{}},
+		{"PUT", "/v1/categories/:id", updateCategory, "Update a category", models.Category{}, 200, models.Category{}},
+		{"PATCH", "/v1/categories/:id", patchCategory, "Update part of a category with a JSON merge patch", models.Category{}, 200, models.Category{}},
+		{"DELETE", "/v1/categories/:id", deleteCategory, "Move a category to the trash", nil, 204, nil},
+
+		{"GET", "/v1/plans", listPlans, "List plans", nil, 200, []models.Plan{}},
+		{"POST", "/v1/plans", idempotent(createPlan), "Create a plan", models.Plan{}, 201, models.Plan{}},
+		{"GET", "/v1/plans/:id", retrievePlan, "Retrieve a plan", nil, 200, models.Plan{}},
+		{"PUT", "/v1/plans/:id", updatePlan, "Update a plan", models.Plan{}, 200, models.Plan{}},
+		{"PATCH", "/v1/plans/:id", patchPlan, "Update part of a plan with a JSON merge patch", models.Plan{}, 200, models.Plan{}},
+		{"DELETE", "/v1/plans/:id", deletePlan, "Move a plan to the trash", nil, 204, nil},
+
+		{"GET", "/v1/budgets", listBudgets, "List budgets", nil, 200, []models.Budget{}},
+		{"POST", "/v1/budgets", idempotent(createBudget), "Create a budget", models.Budget{}, 201, models.Budget{}},
+		{"GET", "/v1/budgets/:id", retrieveBudget, "Retrieve a budget", nil, 200, models.Budget{}},
+		{"GET", "/v1/budgets/:id/totals", retrieveBudgetTotals, "Total a budget's bills, expenses, and savings by category", nil, 200, []models.CategoryTotal{}},
+		{"PUT", "/v1/budgets/:id", updateBudget, "Update a budget", models.Budget{}, 200, models.Budget{}},
+		{"PATCH", "/v1/budgets/:id", patchBudget, "Update part of a budget with a JSON merge patch", models.Budget{}, 200, models.Budget{}},
+		{"DELETE", "/v1/budgets/:id", deleteBudget, "Move a budget to the trash", nil, 204, nil},
+
+		{"GET", "/v1/trash", listTrash, "List deleted resources that can still be restored", nil, 200, []models.TrashItem{}},
+		{"POST", "/v1/trash/:resource/:id/restore", restoreFromTrash, "Restore a deleted resource; the response is the restored resource", nil, 200, nil},
+
+		{"POST", "/v1/batch", idempotent(runBatch), "Create, update, and delete several resources at once", models.BatchRequest{}, 200, models.BatchResponse{}},
+
+		{"GET", "/v1/openapi.json", serveOpenAPI, "Get this OpenAPI specification", nil, 200, nil},
+	}
+	all = append(all, revisionRoutes("/v1/categories", "category", categoryRevisions)...)
+	all = append(all, revisionRoutes("/v1/plans", "plan", planRevisions)...)
+	all = append(all, revisionRoutes("/v1/budgets", "budget", budgetRevisions)...)
+	all = append(all, lineItemRoutes("/v1/plans", "plan", planItems)...)
+	all = append(all, lineItemRoutes("/v1/budgets", "budget", budgetItems)...)
+	return all
 }
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/budgets"
+	"github.com/hjkelly/zbbapi/services/plans"
+	"github.com/julienschmidt/httprouter"
+)
+
+// lineItemSections are the lists within plans and budgets whose line items can be addressed by ID.
+var lineItemSections = []string{"incomes", "bills", "expenses", "savings"}
+
+// lineItemService holds the functions a service provides for working with individual line items.
+type lineItemService struct {
+	retrieve func(caller common.Caller, id, section, itemID string) (interface{}, error)
+	update   func(caller common.Caller, id, section, itemID string, item []byte, expectedVersion int) (interface{}, error)
+	patch    func(caller common.Caller, id, section, itemID string, patch []byte, expectedVersion int) (interface{}, error)
+	delete   func(caller common.Caller, id, section, itemID string, expectedVersion int) error
+	// itemModels holds a zero value of each section's line item type, for documentation.
+	itemModels map[string]interface{}
+}
+
+var planItems = lineItemService{
+	retrieve: plans.RetrieveItem,
+	update:   plans.UpdateItem,
+	patch:    plans.PatchItem,
+	delete:   plans.DeleteItem,
+	itemModels: map[string]interface{}{
+		"incomes":  models.PlannedIncome{},
+		"bills":    models.PlannedBill{},
+		"expenses": models.PlannedExpense{},
+		"savings":  models.PlannedSaving{},
+	},
+}
+
+var budgetItems = lineItemService{
+	retrieve: budgets.RetrieveItem,
+	update:   budgets.UpdateItem,
+	patch:    budgets.PatchItem,
+	delete:   budgets.DeleteItem,
+	itemModels: map[string]interface{}{
+		"incomes":  models.NameAndAmount{},
+		"bills":    models.BudgetBill{},
+		"expenses": models.NameAndAmount{},
+		"savings":  models.NameAndAmount{},
+	},
+}
+
+// lineItemRoutes lists GET/PUT/PATCH/DELETE for a single line item in each section, e.g. /v1/plans/:id/bills/:itemId.
+func lineItemRoutes(basePath, parentName string, service lineItemService) []route {
+	result := make([]route, 0, 4*len(lineItemSections))
+	for _, section := range lineItemSections {
+		path := basePath + "/:id/" + section + "/:itemId"
+		model := service.itemModels[section]
+		result = append(result,
+			route{"GET", path, retrieveLineItem(service, section), "Retrieve one of a " + parentName + "'s " + section, nil, 200, model},
+			route{"PUT", path, updateLineItem(service, section), "Update one of a " + parentName + "'s " + section, model, 200, model},
+			route{"PATCH", path, patchLineItem(service, section), "Update part of one of a " + parentName + "'s " + section + " with a JSON merge patch", model, 200, model},
+			route{"DELETE", path, deleteLineItem(service, section), "Delete one of a " + parentName + "'s " + section, nil, 204, nil},
+		)
+	}
+	return result
+}
+
+func retrieveLineItem(service lineItemService, section string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		result, err := service.retrieve(callerOf(r), params.ByName("id"), section, params.ByName("itemId"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+	}
+}
+
+func updateLineItem(service lineItemService, section string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// Make sure we're changing the version the client expects, if they told us which one.
+		expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		// Read the replacement line item.
+		item, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			common.WriteErrorResponse(w, common.ParseErr)
+			return
+		}
+		err = common.CheckStrict(item, service.itemModels[section])
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		// Update according to the URL.
+		result, err := service.update(callerOf(r), params.ByName("id"), section, params.ByName("itemId"), item, expectedVersion)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+	}
+}
+
+func patchLineItem(service lineItemService, section string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// Make sure we're changing the version the client expects, if they told us which one.
+		expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		// Read the merge patch; it gets applied to the stored line item before validation.
+		err = common.CheckMergePatchType(r.Header.Get("Content-Type"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		patch, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			common.WriteErrorResponse(w, common.ParseErr)
+			return
+		}
+		err = common.CheckStrict(patch, service.itemModels[section])
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		// Update according to the URL.
+		result, err := service.patch(callerOf(r), params.ByName("id"), section, params.ByName("itemId"), patch, expectedVersion)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+	}
+}
+
+func deleteLineItem(service lineItemService, section string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// Make sure we're changing the version the client expects, if they told us which one.
+		expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		err = service.delete(callerOf(r), params.ByName("id"), section, params.ByName("itemId"), expectedVersion)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 204, nil)
+	}
+}
@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// Localize is negroni middleware that picks the locale for the request's messages from its Accept-Language header, falling back to English. It's put in the request's context and the Content-Language response header, where error responses look for it.
+func Localize(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	locale := common.NegotiateLocale(r.Header.Get("Accept-Language"))
+	w.Header().Set(common.ContentLanguageHeader, locale)
+	w.Header().Add("Vary", "Accept-Language")
+	next(w, r.WithContext(common.WithLocale(r.Context(), locale)))
+}
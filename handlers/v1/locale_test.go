@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1/users", nil)
+	r.Header.Set("Accept-Language", "es-MX,es;q=0.9,en;q=0.8")
+	Localize(recorder, r, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "es", common.GetLocale(r.Context()))
+		common.WriteErrorResponse(w, common.NewValidationError("email", common.MissingCode, "email"))
+	})
+	assert.Equal(t, "es", recorder.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", recorder.Header().Get("Vary"))
+	assert.Contains(t, recorder.Body.String(), "Debes proporcionar un correo electrónico.")
+
+	recorder = httptest.NewRecorder()
+	Localize(recorder, httptest.NewRequest("POST", "/v1/users", nil), func(w http.ResponseWriter, r *http.Request) {
+		common.WriteErrorResponse(w, common.NewValidationError("email", common.MissingCode, "email"))
+	})
+	assert.Equal(t, "en", recorder.Header().Get("Content-Language"))
+	assert.Contains(t, recorder.Body.String(), "You must provide an email address.")
+}
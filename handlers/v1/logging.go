@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/urfave/negroni"
+)
+
+// maxRequestIDLength keeps clients from stuffing the logs through their request IDs.
+const maxRequestIDLength = 128
+
+// RequestLogging is negroni middleware that gives each request an ID, echoes it in the X-Request-ID response header, and writes an access log line once the request is handled. Everything that logs while handling the request can tag its lines with the same ID.
+func RequestLogging(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	requestID := r.Header.Get(common.RequestIDHeader)
+	if !isRequestID(requestID) {
+		requestID = string(models.NewSafeUUID())
+	}
+	w.Header().Set(common.RequestIDHeader, requestID)
+	r = r.WithContext(common.WithRequestID(r.Context(), requestID))
+
+	start := time.Now()
+	next(w, r)
+
+	logger := common.LoggerFor(r.Context()).
+		With("method", r.Method).
+		With("path", r.URL.Path).
+		With("remoteAddr", r.RemoteAddr).
+		With("durationMs", time.Since(start).Seconds()*1000)
+	if rw, ok := w.(negroni.ResponseWriter); ok {
+		logger = logger.With("status", rw.Status()).With("bytes", rw.Size())
+	}
+	logger.Info("%s %s", r.Method, r.URL.Path)
+}
+
+// Says whether a client's request ID is one we can use: not empty, not too long, and only printable ASCII.
+func isRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
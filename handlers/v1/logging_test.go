@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogging(t *testing.T) {
+	var seen string
+	next := func(w http.ResponseWriter, r *http.Request) {
+		seen = common.GetRequestID(r.Context())
+	}
+
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/plans", nil)
+	r.Header.Set(common.RequestIDHeader, "abc-123")
+	RequestLogging(recorder, r, next)
+	assert.Equal(t, "abc-123", seen, "a client's own request ID should be used")
+	assert.Equal(t, "abc-123", recorder.Header().Get(common.RequestIDHeader))
+
+	for _, bad := range []string{"", "has spaces", strings.Repeat("x", maxRequestIDLength+1)} {
+		recorder = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", "/v1/plans", nil)
+		r.Header.Set(common.RequestIDHeader, bad)
+		RequestLogging(recorder, r, next)
+		assert.NotEqual(t, bad, seen, "CASE: %q", bad)
+		assert.NotEmpty(t, seen, "CASE: %q", bad)
+		assert.Equal(t, seen, recorder.Header().Get(common.RequestIDHeader), "CASE: %q", bad)
+	}
+}
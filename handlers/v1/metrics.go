@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hjkelly/zbbapi/metrics"
+	"github.com/julienschmidt/httprouter"
+	"github.com/urfave/negroni"
+)
+
+var requestCount = metrics.NewCounter("zbbapi_http_requests_total", "How many requests we've handled, by method, route, and status.", "method", "route", "status")
+var requestDuration = metrics.NewHistogram("zbbapi_http_request_duration_seconds", "How long requests take to handle, by method, route, and status.", metrics.DefaultBuckets, "method", "route", "status")
+
+// unmatchedRoute labels requests that didn't match any route, so clients probing random paths can't create endless label values.
+const unmatchedRoute = "unmatched"
+
+// matchedRoute is filled in once the router has matched a request, so the middleware that started timing it knows which route it was.
+type matchedRoute struct {
+	path string
+}
+
+type matchedRouteKey struct{}
+
+// Instrument is negroni middleware that counts and times each request, labeled by the route pattern it matched (like /v1/plans/:id) and its status.
+func Instrument(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	matched := &matchedRoute{path: unmatchedRoute}
+	r = r.WithContext(context.WithValue(r.Context(), matchedRouteKey{}, matched))
+	rw, ok := w.(negroni.ResponseWriter)
+	if !ok {
+		rw = negroni.NewResponseWriter(w)
+	}
+
+	start := time.Now()
+	next(rw, r)
+
+	status := strconv.Itoa(rw.Status())
+	requestCount.Inc(r.Method, matched.path, status)
+	requestDuration.Observe(time.Since(start).Seconds(), r.Method, matched.path, status)
+}
+
+// routed tells Instrument which route handled the request.
+func routed(path string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if matched, ok := r.Context().Value(matchedRouteKey{}).(*matchedRoute); ok {
+			matched.path = path
+		}
+		handle(w, r, params)
+	}
+}
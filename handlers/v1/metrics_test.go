@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	router := httprouter.New()
+	router.Handle("GET", "/v1/things/:id", routed("/v1/things/:id", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(418)
+	}))
+
+	for _, path := range []string{"/v1/things/abc", "/v1/things/def", "/nowhere"} {
+		Instrument(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil), router.ServeHTTP)
+	}
+
+	assert.Equal(t, 2.0, requestCount.Value("GET", "/v1/things/:id", "418"), "requests should be labeled by route pattern, not path")
+	assert.Equal(t, 1.0, requestCount.Value("GET", unmatchedRoute, "404"))
+	assert.Equal(t, uint64(2), requestDuration.Count("GET", "/v1/things/:id", "418"))
+}
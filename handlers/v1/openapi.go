@@ -0,0 +1,436 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/julienschmidt/httprouter"
+	uuid "github.com/satori/go.uuid"
+)
+
+// schema is a JSON Schema object as used by OpenAPI.
+type schema map[string]interface{}
+
+var openAPIOnce sync.Once
+var openAPIDoc map[string]interface{}
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	openAPIOnce.Do(func() {
+		openAPIDoc = buildOpenAPI(routes())
+	})
+	common.WriteResponse(w, 200, openAPIDoc)
+}
+
+// buildOpenAPI generates an OpenAPI 3 document describing the routes, with a schema for each model they use.
+func buildOpenAPI(routes []route) map[string]interface{} {
+	gen := &schemaGenerator{components: map[string]schema{}}
+	// Errors can come back from any route, so always describe them.
+	gen.schemaFor(reflect.TypeOf(common.ErrorEnvelope{}))
+	// GET /v1/categories?tree=true returns these instead of a flat list.
+	gen.schemaFor(reflect.TypeOf(models.CategoryNode{}))
+
+	paths := map[string]map[string]interface{}{}
+	for _, r := range routes {
+		path := openAPIPath(r.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(r.Method)] = gen.operation(r)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Zero-Balance Budget API",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+		// Every route requires a token unless it says otherwise.
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+		},
+	}
+}
+
+// openAPIPath turns httprouter's /v1/plans/:id into OpenAPI's /v1/plans/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[idx] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// queryParameters lists the optional query parameters each route understands, keyed by method and path.
+var queryParameters = map[string][]interface{}{
+	"GET /v1/categories":        {treeParameter},
+	"GET /v1/plans":             {expandParameter},
+	"GET /v1/plans/:id":         {expandParameter},
+	"GET /v1/budgets":           {expandParameter},
+	"GET /v1/budgets/:id":       {expandParameter},
+	"DELETE /v1/categories/:id": {reassignToParameter},
+	"POST /v1/plans":            {dryRunParameter},
+	"PUT /v1/plans/:id":         {dryRunParameter},
+	"PATCH /v1/plans/:id":       {dryRunParameter},
+	"POST /v1/budgets":          {dryRunParameter},
+	"PUT /v1/budgets/:id":       {dryRunParameter},
+	"PATCH /v1/budgets/:id":     {dryRunParameter},
+}
+
+// conflictResponses describes why routes that can refuse with 409 Conflict would do so.
+var conflictResponses = map[string]string{
+	"DELETE /v1/categories/:id": "Plans or budgets still refer to this category, and reassignTo wasn't provided.",
+}
+
+var treeParameter = map[string]interface{}{
+	"name":        "tree",
+	"in":          "query",
+	"description": "Set to true to list categories as a tree of CategoryNodes, with each category's subcategories under it.",
+	"schema":      schema{"type": "boolean"},
+}
+
+var expandParameter = map[string]interface{}{
+	"name":        "expand",
+	"in":          "query",
+	"description": "Embed referenced resources in the response. Use \"category\" to include each line item's category.",
+	"schema":      schema{"type": "string", "enum": []string{"category"}},
+}
+
+var reassignToParameter = map[string]interface{}{
+	"name":        "reassignTo",
+	"in":          "query",
+	"description": "If plans or budgets still refer to this category, point them at the category with this ID instead. Without it, deleting a category that's in use fails with 409.",
+	"schema":      schema{"type": "string", "format": "uuid"},
+}
+
+var dryRunParameter = map[string]interface{}{
+	"name":        "dryRun",
+	"in":          "query",
+	"description": "Set to true to validate the change and see the result, with its totals and warnings, without saving anything.",
+	"schema":      schema{"type": "boolean"},
+}
+
+// Says whether the route takes the dryRun query parameter.
+func takesDryRun(r route) bool {
+	for _, parameter := range queryParameters[r.Method+" "+r.Path] {
+		if parameter.(map[string]interface{})["name"] == dryRunParameter["name"] {
+			return true
+		}
+	}
+	return false
+}
+
+// Describes a single route: its path parameters, request body, and the responses it can give.
+func (gen *schemaGenerator) operation(r route) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary": r.Summary,
+	}
+	public := publicRoutes[r.Method+" "+r.Path]
+	if public {
+		// Overrides the document's default of requiring a token.
+		op["security"] = []interface{}{}
+	} else {
+		op["description"] = scopeDescription(r)
+	}
+
+	parameters := []interface{}{}
+	for _, segment := range strings.Split(r.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     segment[1:],
+				"in":       "path",
+				"required": true,
+				"schema":   schema{"type": "string"},
+			})
+		}
+	}
+	parameters = append(parameters, queryParameters[r.Method+" "+r.Path]...)
+	required := requiredRole(r.Method, r.Path)
+	if required != "" {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        householdHeader,
+			"in":          "header",
+			"description": "The household whose data to work with. Leave it out to work with your own.",
+			"schema":      schema{"type": "string"},
+		})
+	}
+	if usesIfMatch(r) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        "If-Match",
+			"in":          "header",
+			"description": "Only make the change if the resource is still at this version (its ETag).",
+			"schema":      schema{"type": "string"},
+		})
+	}
+	if r.Method == "POST" && r.Request != nil && !public {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        "Idempotency-Key",
+			"in":          "header",
+			"description": "Lets you safely retry this request; retries with the same key get the original response.",
+			"schema":      schema{"type": "string"},
+		})
+	}
+	parameters = append(parameters, map[string]interface{}{
+		"name":        "Accept-Language",
+		"in":          "header",
+		"description": "The language for error messages: " + strings.Join(common.Locales(), ", ") + ". Anything else gets English.",
+		"schema":      schema{"type": "string"},
+	})
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	if r.Request != nil {
+		contentType := "application/json"
+		if r.Method == "PATCH" {
+			contentType = common.MergePatchContentType
+		}
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				contentType: map[string]interface{}{
+					"schema": gen.schemaFor(reflect.TypeOf(r.Request)),
+				},
+			},
+		}
+	}
+
+	success := map[string]interface{}{
+		"description": http.StatusText(r.Status),
+	}
+	if r.Response != nil {
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": gen.schemaFor(reflect.TypeOf(r.Response)),
+			},
+		}
+	}
+	responses := map[string]interface{}{
+		strconv.Itoa(r.Status): success,
+		"default":              errorResponse("Something went wrong; the code explains what."),
+	}
+	if r.Status == http.StatusCreated && takesDryRun(r) {
+		responses["200"] = map[string]interface{}{
+			"description": "With dryRun, what would have been created. Nothing is saved.",
+			"content":     success["content"],
+		}
+	}
+	if !public {
+		responses["401"] = errorResponse("You didn't provide a valid token.")
+	}
+	if required == models.RoleEditor || ownerRoutes[r.Method+" "+r.Path] {
+		responses["403"] = errorResponse("Your role in the household, or your access token's scopes, don't allow this.")
+	} else if !public {
+		responses["403"] = errorResponse("Your access token's scopes don't allow this.")
+	}
+	if strings.Contains(r.Path, ":") {
+		responses["404"] = errorResponse("Couldn't find what you referenced.")
+	}
+	if r.Request != nil {
+		responses["400"] = errorResponse("Couldn't parse the request body.")
+		responses["422"] = errorResponse("One or more fields was either missing or invalid.")
+		if r.Method == "PATCH" {
+			responses["415"] = errorResponse("The body wasn't sent as a JSON merge patch.")
+		}
+	}
+	if description, ok := conflictResponses[r.Method+" "+r.Path]; ok {
+		responses["409"] = errorResponse(description)
+	}
+	if r.Path == "/readyz" {
+		responses["503"] = map[string]interface{}{
+			"description": "Not ready; the checks say why.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": gen.schemaFor(reflect.TypeOf(r.Response)),
+				},
+			},
+		}
+	}
+	if isRevert(r) {
+		responses["422"] = errorResponse("The earlier revision isn't valid anymore, e.g. because a category it refers to was deleted.")
+	}
+	if usesIfMatch(r) {
+		responses["412"] = errorResponse("The resource changed since the version in If-Match; the error's current field holds its current representation.")
+	}
+	rateLimited := errorResponse("You've made too many requests. Retry-After says how many seconds to wait.")
+	rateLimited["headers"] = map[string]interface{}{
+		"Retry-After": map[string]interface{}{"schema": schema{"type": "integer"}},
+	}
+	responses["429"] = rateLimited
+	op["responses"] = responses
+	return op
+}
+
+// Explains what an access token needs to use the route.
+func scopeDescription(r route) string {
+	scopes, allowed := tokenScopes(r.Method, r.Path, nil)
+	if !allowed {
+		return "Access tokens can't be used for this; log in instead."
+	} else if strings.Contains(r.Path, "/:resource/") {
+		return "Access tokens need the write scope for the kind of resource, like plans:write."
+	} else if len(scopes) == 0 {
+		return "Access tokens need the write scope for each kind of resource the operations change, like plans:write."
+	}
+	return "Access tokens need these scopes: " + strings.Join(scopes, ", ") + "."
+}
+
+// Writes to an existing resource can be made conditional on its version.
+func usesIfMatch(r route) bool {
+	return r.Method == "PUT" || r.Method == "PATCH" || r.Method == "DELETE" || isRevert(r)
+}
+
+func isRevert(r route) bool {
+	return r.Method == "POST" && strings.HasSuffix(r.Path, "/revert")
+}
+
+// Describes a response with an error envelope, which is how every error comes back.
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": schema{"$ref": "#/components/schemas/ErrorEnvelope"},
+			},
+		},
+	}
+}
+
+// schemaGenerator builds schemas from our models, collecting each named struct as a reusable component.
+type schemaGenerator struct {
+	components map[string]schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var dateType = reflect.TypeOf(common.Date{})
+var safeUUIDType = reflect.TypeOf(models.SafeUUID(""))
+var uuidType = reflect.TypeOf(uuid.UUID{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var scheduleType = reflect.TypeOf(models.Schedule{})
+
+// schemaFor describes how values of this type look in JSON.
+func (gen *schemaGenerator) schemaFor(t reflect.Type) schema {
+	switch t {
+	case timeType:
+		return schema{"type": "string", "format": "date-time"}
+	case dateType:
+		return schema{"type": "string", "format": "date"}
+	case safeUUIDType, uuidType:
+		return schema{"type": "string", "format": "uuid"}
+	case rawMessageType:
+		return schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return gen.schemaFor(t.Elem())
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": gen.schemaFor(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": gen.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return gen.structRef(t)
+	default:
+		// interface{} and anything else we can't say more about.
+		return schema{}
+	}
+}
+
+// Adds the struct as a component (if it isn't one already) and refers to it.
+func (gen *schemaGenerator) structRef(t reflect.Type) schema {
+	name := t.Name()
+	if _, ok := gen.components[name]; !ok {
+		// Reserve the name first, in case the struct refers to itself.
+		gen.components[name] = schema{}
+		properties := schema{}
+		gen.addProperties(t, properties)
+		component := schema{"type": "object", "properties": properties}
+		if t == scheduleType {
+			component["oneOf"] = oneOfProperties(properties)
+		}
+		gen.components[name] = component
+	}
+	return schema{"$ref": "#/components/schemas/" + name}
+}
+
+// Adds each of the struct's JSON fields, following encoding/json's rules: untagged embedded structs are flattened, tagged ones become a property.
+func (gen *schemaGenerator) addProperties(t reflect.Type, properties schema) {
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				gen.addProperties(fieldType, properties)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := gen.schemaFor(field.Type)
+		if _, isRef := property["$ref"]; field.Tag.Get("readonly") == "true" && !isRef {
+			property["readOnly"] = true
+		}
+		properties[name] = property
+	}
+}
+
+// jsonFieldName returns the name encoding/json would use for the field (empty if untagged), and false if it's never encoded.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	return strings.Split(tag, ",")[0], true
+}
+
+// A schedule has several optional properties, but exactly one of them must be provided.
+func oneOfProperties(properties schema) []interface{} {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	choices := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		choices = append(choices, schema{"required": []string{name}})
+	}
+	return choices
+}
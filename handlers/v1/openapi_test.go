@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Reads the models package's source (rather than using reflection like the generator does) to find each struct and the JSON properties it should have.
+func TestOpenAPIHasEveryModelField(t *testing.T) {
+	structs := parseModelStructs(t)
+	doc := buildOpenAPI(routes())
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]schema)
+
+	embedded := map[string]bool{}
+	for _, fields := range structs {
+		for _, field := range fields {
+			if field.Names == nil && jsonTagName(field) == "" {
+				if ident, ok := field.Type.(*ast.Ident); ok {
+					embedded[ident.Name] = true
+				}
+			}
+		}
+	}
+
+	for name := range structs {
+		expected := jsonProperties(structs, name)
+		component, ok := components[name]
+		if !ok && len(expected) == 0 {
+			// It's only used internally, so it never appears in JSON.
+			continue
+		}
+		if !ok {
+			assert.True(t, embedded[name], "model %s isn't in the spec, and isn't embedded in another model", name)
+			continue
+		}
+		actual := []string{}
+		for property := range component["properties"].(schema) {
+			actual = append(actual, property)
+		}
+		sort.Strings(actual)
+		assert.Equal(t, expected, actual, "properties of %s in the spec don't match the model", name)
+	}
+}
+
+func TestOpenAPIScheduleIsOneOf(t *testing.T) {
+	doc := buildOpenAPI(routes())
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]schema)
+	oneOf := components["Schedule"]["oneOf"].([]interface{})
+	assert.Equal(t, schema{"required": []string{"halfMonthOnDays"}}, oneOf[0])
+	assert.Len(t, oneOf, 5)
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/v1/plans/{id}/bills/{itemId}", openAPIPath("/v1/plans/:id/bills/:itemId"))
+}
+
+// Maps each struct type declared in the models package to its fields.
+func parseModelStructs(t *testing.T) map[string][]*ast.Field {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../../models", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("Couldn't parse the models package: %s", err.Error())
+	}
+	structs := map[string][]*ast.Field{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok || !spec.Name.IsExported() {
+					return true
+				}
+				if structType, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = structType.Fields.List
+				}
+				return true
+			})
+		}
+	}
+	return structs
+}
+
+// Lists the JSON property names of a struct, flattening untagged embedded structs the way encoding/json does.
+func jsonProperties(structs map[string][]*ast.Field, name string) []string {
+	properties := []string{}
+	for _, field := range structs[name] {
+		tagName := jsonTagName(field)
+		if tagName == "-" {
+			continue
+		}
+		if field.Names == nil {
+			ident, isLocal := field.Type.(*ast.Ident)
+			if tagName == "" && isLocal {
+				properties = append(properties, jsonProperties(structs, ident.Name)...)
+				continue
+			}
+			if tagName == "" {
+				tagName = field.Type.(*ast.SelectorExpr).Sel.Name
+			}
+			properties = append(properties, tagName)
+			continue
+		}
+		for _, fieldName := range field.Names {
+			if !fieldName.IsExported() {
+				continue
+			}
+			if tagName == "" {
+				properties = append(properties, fieldName.Name)
+			} else {
+				properties = append(properties, tagName)
+			}
+		}
+	}
+	sort.Strings(properties)
+	return properties
+}
+
+// Returns the name from the field's json tag, if it has one.
+func jsonTagName(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+}
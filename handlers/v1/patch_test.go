@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// Each resource that can be patched, with a patch that only touches read-only fields.
+var patchHandlers = []struct {
+	resource string
+	handle   httprouter.Handle
+	readOnly string
+	fields   []string
+}{
+	{"categories", patchCategory, `{"id":"abc"}`, []string{"id"}},
+	{"plans", patchPlan, `{"id":"abc","totals":{}}`, []string{"id", "totals"}},
+	{"budgets", patchBudget, `{"id":"abc","Balance":{"amount":1}}`, []string{"Balance", "id"}},
+}
+
+// Sends a PATCH straight to the handler, as a caller who's already been authenticated.
+func sendPatch(handle httprouter.Handle, contentType, patch string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(patch))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	r = r.WithContext(common.WithCaller(r.Context(), common.Caller{TenantID: "home", UserID: "me"}))
+	recorder := httptest.NewRecorder()
+	handle(recorder, r, httprouter.Params{{Key: "id", Value: "abc"}})
+	return recorder
+}
+
+func TestPatchRejectsOtherContentTypes(t *testing.T) {
+	for _, testCase := range patchHandlers {
+		for _, contentType := range []string{"", "text/plain", "application/json-patch+json"} {
+			recorder := sendPatch(testCase.handle, contentType, `{}`)
+			assert.Equal(t, 415, recorder.Code, "CASE: %s as %q", testCase.resource, contentType)
+		}
+	}
+}
+
+func TestPatchRejectsReadOnlyFields(t *testing.T) {
+	for _, testCase := range patchHandlers {
+		recorder := sendPatch(testCase.handle, common.MergePatchContentType, testCase.readOnly)
+		assert.Equal(t, 422, recorder.Code, "CASE: %s", testCase.resource)
+		var envelope common.ErrorEnvelope
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &envelope), "CASE: %s", testCase.resource)
+		fields := []string{}
+		for _, field := range envelope.Fields {
+			assert.Equal(t, common.ReadOnlyFieldCode, field.Code, "CASE: %s %s", testCase.resource, field.FieldName)
+			fields = append(fields, field.FieldName)
+		}
+		assert.Equal(t, testCase.fields, fields, "CASE: %s", testCase.resource)
+	}
+}
+
+// Patches through the real routes: null removes a field, and what isn't mentioned is left alone.
+func TestPatchAppliesMergePatches(t *testing.T) {
+	defer useTestDatabases(t)()
+	router := httprouter.New()
+	RegisterHandlers(router)
+	token := signUp(t, "patcher")
+
+	parent := sendAs(router, token, "POST", "/v1/categories", `{"name":"Housing"}`)
+	var parentDoc struct {
+		ID string `json:"id"`
+	}
+	assert.Nil(t, json.Unmarshal(parent.Body.Bytes(), &parentDoc))
+
+	for _, testCase := range []struct {
+		resource string
+		body     string
+		patch    string
+		removed  string
+		kept     string
+	}{
+		{"categories", `{"name":"Rent","parentId":"` + parentDoc.ID + `"}`, `{"parentId":null}`, "parentId", "name"},
+		{"plans", `{"savingsStrategy":"shared","expenses":[{"name":"Groceries","amount":40000}]}`, `{"expenses":null}`, "expenses", "savingsStrategy"},
+		{"budgets", `{"startDate":"2018-05-12","endDate":"2018-05-26","expenses":[{"name":"Groceries","amount":20000}]}`, `{"expenses":null}`, "expenses", "startDate"},
+	} {
+		created := sendAs(router, token, "POST", "/v1/"+testCase.resource, testCase.body)
+		if !assert.Equal(t, 201, created.Code, "CASE: %s: %s", testCase.resource, created.Body.String()) {
+			continue
+		}
+		before := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(created.Body.Bytes(), &before))
+
+		patched := sendAs(router, token, "PATCH", "/v1/"+testCase.resource+"/"+before["id"].(string), testCase.patch)
+		if !assert.Equal(t, 200, patched.Code, "CASE: %s: %s", testCase.resource, patched.Body.String()) {
+			continue
+		}
+		after := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(patched.Body.Bytes(), &after))
+		assert.NotEmpty(t, before[testCase.removed], "CASE: %s", testCase.resource)
+		assert.Empty(t, after[testCase.removed], "CASE: %s: null should remove %s", testCase.resource, testCase.removed)
+		assert.Equal(t, before[testCase.kept], after[testCase.kept], "CASE: %s: %s shouldn't change", testCase.resource, testCase.kept)
+	}
+}
@@ -1,7 +1,7 @@
 package v1
 
 import (
-	"encoding/json"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/hjkelly/zbbapi/common"
@@ -11,24 +11,50 @@ import (
 )
 
 func listPlans(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	results, err := plans.List()
+	results, err := plans.List(callerOf(r))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
 	}
+	if common.WantsExpansion(r, "category") {
+		expanding := make([]*models.Plan, 0, len(results))
+		for idx := range results {
+			expanding = append(expanding, &results[idx])
+		}
+		err = plans.ExpandCategories(callerOf(r), expanding...)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+	}
 	common.WriteResponse(w, 200, results)
 }
 
 func createPlan(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Parse the request body.
 	var plan models.Plan
-	err := json.NewDecoder(r.Body).Decode(&plan)
+	err = common.DecodeStrict(r.Body, &plan)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
 	}
+	// Just show what would be saved, if that's all they want.
+	if dryRun {
+		result, err := plans.Preview(callerOf(r), plan)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+		return
+	}
 	// Save it.
-	result, err := plans.Create(plan)
+	result, err := plans.Create(callerOf(r), plan)
 	if err != nil {
 		// TODO: Handle validation vs. DB error...
 		common.WriteErrorResponse(w, err)
@@ -38,24 +64,87 @@ func createPlan(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 }
 
 func retrievePlan(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	result, err := plans.Retrieve(params.ByName("id"))
+	result, err := plans.Retrieve(callerOf(r), params.ByName("id"))
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
 	}
+	if common.WantsExpansion(r, "category") {
+		err = plans.ExpandCategories(callerOf(r), result)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+	}
 	common.WriteResponse(w, 200, result)
 }
 
 func updatePlan(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
 	// Parse the request body.
 	var plan models.Plan
-	err := json.NewDecoder(r.Body).Decode(&plan)
+	err = common.DecodeStrict(r.Body, &plan)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Update according to the URL, or just show what the update would be.
+	update := plans.UpdateID
+	if dryRun {
+		update = plans.PreviewUpdateID
+	}
+	result, err := update(callerOf(r), params.ByName("id"), plan, expectedVersion)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
+
+func patchPlan(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	dryRun, err := common.WantsDryRun(r)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Read the merge patch; it gets applied to the stored Plan before validation.
+	err = common.CheckMergePatchType(r.Header.Get("Content-Type"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	patch, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		common.WriteErrorResponse(w, common.ParseErr)
 		return
 	}
-	// Update according to the URL.
-	result, err := plans.UpdateID(params.ByName("id"), plan)
+	err = common.CheckStrict(patch, models.Plan{})
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	// Update according to the URL, or just show what the update would be.
+	patchID := plans.PatchID
+	if dryRun {
+		patchID = plans.PreviewPatchID
+	}
+	result, err := patchID(callerOf(r), params.ByName("id"), patch, expectedVersion)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -64,7 +153,13 @@ func updatePlan(w http.ResponseWriter, r *http.Request, params httprouter.Params
 }
 
 func deletePlan(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	err := plans.Delete(params.ByName("id"))
+	// Make sure we're changing the version the client expects, if they told us which one.
+	expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	err = plans.Delete(callerOf(r), params.ByName("id"), expectedVersion)
 	if err != nil {
 		common.WriteErrorResponse(w, err)
 		return
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/services/ratelimit"
+	"github.com/hjkelly/zbbapi/services/users"
+)
+
+// RateLimit is negroni middleware that gives each client a token bucket for each class of route, and turns them away with a 429 once it's empty.
+func RateLimit(store ratelimit.Store) func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		class := rateLimitClass(r)
+		limit := config.GetConfig().RateLimits[class]
+		if limit.Requests <= 0 {
+			next(w, r)
+			return
+		}
+
+		allowed, wait, err := store.Take(class+"/"+clientKey(r, class), limit, time.Now())
+		if err != nil {
+			// Better to let a few extra requests through than to turn everyone away because the store is struggling.
+			common.LoggerFor(r.Context()).Warn("Couldn't check the rate limit: %s", err.Error())
+			next(w, r)
+			return
+		}
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+			common.WriteErrorResponse(w, common.RateLimitedErr)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Says which limit applies: logging in and signing up are limited most, to slow down password guessing; then writes; then reads.
+func rateLimitClass(r *http.Request) string {
+	if r.Method == "POST" && (r.URL.Path == "/v1/sessions" || r.URL.Path == "/v1/users") {
+		return "auth"
+	}
+	if r.Method == "GET" || r.Method == "HEAD" {
+		return "read"
+	}
+	return "write"
+}
+
+// Identifies the client. This runs before authentication, so only a token we can check without the database (one they got by logging in) gets its own limit, shared by the user wherever they make requests from; anything else could be made up to get a fresh limit, so those clients are limited by IP address, as are access tokens. Logging in and signing up are always limited by IP address, since that's what slows down password guessing.
+func clientKey(r *http.Request, class string) string {
+	if class != "auth" {
+		if userID, err := users.TokenUserID(bearerToken(r.Header.Get("Authorization"))); err == nil {
+			return "user:" + userID
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Rounds up, since Retry-After is in whole seconds and retrying early would just be turned away again.
+func retryAfterSeconds(wait time.Duration) int {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/services/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitClass(t *testing.T) {
+	cases := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"POST", "/v1/sessions", "auth"},
+		{"POST", "/v1/users", "auth"},
+		{"GET", "/v1/users/me", "read"},
+		{"HEAD", "/v1/plans", "read"},
+		{"POST", "/v1/plans", "write"},
+		{"DELETE", "/v1/plans/abc", "write"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.path, nil)
+		assert.Equal(t, c.expected, rateLimitClass(r), "CASE: %s %s", c.method, c.path)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	first := httptest.NewRequest("GET", "/v1/plans", nil)
+	first.RemoteAddr = "203.0.113.7:5000"
+	assert.Equal(t, "ip:203.0.113.7", clientKey(first, "read"))
+
+	second := httptest.NewRequest("GET", "/v1/plans", nil)
+	second.RemoteAddr = "203.0.113.7:5001"
+	assert.Equal(t, clientKey(first, "read"), clientKey(second, "read"), "the port shouldn't matter")
+
+	first.Header.Set("Authorization", "Bearer abc")
+	second.Header.Set("Authorization", "Bearer def")
+	assert.Equal(t, "ip:203.0.113.7", clientKey(first, "read"), "a token we can't verify shouldn't get its own limit")
+	assert.Equal(t, clientKey(first, "read"), clientKey(second, "read"), "made-up tokens shouldn't get a fresh limit")
+	assert.Equal(t, "ip:203.0.113.7", clientKey(first, "auth"))
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, retryAfterSeconds(0))
+	assert.Equal(t, 1, retryAfterSeconds(200*time.Millisecond))
+	assert.Equal(t, 1, retryAfterSeconds(time.Second))
+	assert.Equal(t, 2, retryAfterSeconds(1001*time.Millisecond))
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := config.GetConfig()
+	original := cfg.RateLimits["write"]
+	defer func() { cfg.RateLimits["write"] = original }()
+	cfg.RateLimits["write"] = config.RateLimit{Requests: 1, Per: time.Minute}
+
+	middleware := RateLimit(ratelimit.NewMemoryStore())
+	calls := 0
+	next := func(w http.ResponseWriter, r *http.Request) { calls++ }
+	send := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		middleware(recorder, httptest.NewRequest("POST", "/v1/plans", nil), next)
+		return recorder
+	}
+
+	assert.Equal(t, 200, send().Code)
+	recorder := send()
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), "RATE_LIMITED")
+	assert.Equal(t, 1, calls)
+
+	cfg.RateLimits["write"] = config.RateLimit{}
+	assert.Equal(t, 200, send().Code, "a limit that's off shouldn't stop anyone")
+	assert.Equal(t, 2, calls)
+}
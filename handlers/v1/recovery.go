@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/urfave/negroni"
+)
+
+// Recover is negroni middleware that turns a panic while handling a request into a logged error response, rather than a dropped connection. Panicking with one of our errors, like common.UnavailableErr, responds with that error; anything else is an internal error.
+func Recover(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		} else if p == http.ErrAbortHandler {
+			// The server handles this one itself, by quietly closing the connection.
+			panic(p)
+		}
+		common.LoggerFor(r.Context()).Error("Recovered from a panic: %v\n%s", p, debug.Stack())
+
+		if rw, ok := w.(negroni.ResponseWriter); ok && rw.Written() {
+			// It's too late to change the response; all we can do is cut it short.
+			return
+		}
+		err, ok := p.(*common.BasicError)
+		if !ok || err.ResponseCode() == 500 {
+			err = common.InternalErr
+		}
+		common.WriteErrorResponse(w, err)
+	}()
+	next(w, r)
+}
+
+// NotFound responds to requests for paths we don't have, in the same shape as every other error.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	common.WriteErrorResponse(w, common.NotFoundErr)
+}
+
+// MethodNotAllowed responds to requests for paths we have, but with a method they don't support. The router has already listed the allowed methods in the Allow header.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	common.WriteErrorResponse(w, common.MethodNotAllowedErr)
+}
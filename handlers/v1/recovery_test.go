@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/negroni"
+)
+
+func TestRecover(t *testing.T) {
+	common.SetLogOutput(ioutil.Discard)
+	defer common.SetLogOutput(os.Stdout)
+
+	for _, testCase := range []struct {
+		desc         string
+		panicWith    interface{}
+		expectedCode int
+		expectedErr  string
+	}{
+		{"string", "boom", 500, "INTERNAL_ERROR"},
+		{"unavailable", common.UnavailableErr, 503, "UNAVAILABLE"},
+		{"unregistered error", &common.BasicError{Code: "SECRET", Message: "Details."}, 500, "INTERNAL_ERROR"},
+	} {
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/v1/plans", nil)
+		r.Header.Set(common.RequestIDHeader, "abc-123")
+		RequestLogging(recorder, r, func(w http.ResponseWriter, r *http.Request) {
+			Recover(w, r, func(http.ResponseWriter, *http.Request) { panic(testCase.panicWith) })
+		})
+
+		envelope := common.ErrorEnvelope{}
+		json.NewDecoder(recorder.Body).Decode(&envelope)
+		assert.Equal(t, testCase.expectedCode, recorder.Code, "CASE: %s", testCase.desc)
+		assert.Equal(t, testCase.expectedErr, envelope.Code, "CASE: %s", testCase.desc)
+		assert.Equal(t, "abc-123", envelope.RequestID, "CASE: %s", testCase.desc)
+	}
+}
+
+func TestRecoverAfterWriting(t *testing.T) {
+	common.SetLogOutput(ioutil.Discard)
+	defer common.SetLogOutput(os.Stdout)
+
+	recorder := httptest.NewRecorder()
+	rw := negroni.NewResponseWriter(recorder)
+	Recover(rw, httptest.NewRequest("GET", "/v1/plans", nil), func(w http.ResponseWriter, r *http.Request) {
+		common.WriteResponse(w, 200, map[string]string{})
+		panic("boom")
+	})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "{}\n", recorder.Body.String())
+}
+
+func TestRecoverLetsAbortThrough(t *testing.T) {
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		Recover(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/plans", nil), func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		})
+	})
+}
+
+func TestNotFoundAndMethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NotFound(recorder, httptest.NewRequest("GET", "/v2/nothing", nil))
+	assert.Equal(t, 404, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"NOT_FOUND"`)
+
+	recorder = httptest.NewRecorder()
+	MethodNotAllowed(recorder, httptest.NewRequest("PATCH", "/v1/users", nil))
+	assert.Equal(t, 405, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"code":"METHOD_NOT_ALLOWED"`)
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/budgets"
+	"github.com/hjkelly/zbbapi/services/categories"
+	"github.com/hjkelly/zbbapi/services/plans"
+	"github.com/julienschmidt/httprouter"
+)
+
+// revisionService adapts a service package's revision history to the handlers below.
+type revisionService struct {
+	list     func(caller common.Caller, id string) ([]models.Revision, error)
+	retrieve func(caller common.Caller, id string, version int) (*models.Revision, error)
+	revert   func(caller common.Caller, id string, version, expectedVersion int) (interface{}, error)
+	// model holds a zero value of the resource, for documentation.
+	model interface{}
+}
+
+var categoryRevisions = revisionService{
+	list:     categories.Revisions,
+	retrieve: categories.RetrieveRevision,
+	revert: func(caller common.Caller, id string, version, expectedVersion int) (interface{}, error) {
+		return categories.Revert(caller, id, version, expectedVersion)
+	},
+	model: models.Category{},
+}
+
+var planRevisions = revisionService{
+	list:     plans.Revisions,
+	retrieve: plans.RetrieveRevision,
+	revert: func(caller common.Caller, id string, version, expectedVersion int) (interface{}, error) {
+		return plans.Revert(caller, id, version, expectedVersion)
+	},
+	model: models.Plan{},
+}
+
+var budgetRevisions = revisionService{
+	list:     budgets.Revisions,
+	retrieve: budgets.RetrieveRevision,
+	revert: func(caller common.Caller, id string, version, expectedVersion int) (interface{}, error) {
+		return budgets.Revert(caller, id, version, expectedVersion)
+	},
+	model: models.Budget{},
+}
+
+// revisionRoutes lists the routes for browsing a resource's revision history and reverting to an earlier revision, e.g. /v1/plans/:id/revisions.
+func revisionRoutes(basePath, parentName string, service revisionService) []route {
+	path := basePath + "/:id/revisions"
+	return []route{
+		{"GET", path, listRevisions(service), "List a " + parentName + "'s revisions, newest first", nil, 200, []models.Revision{}},
+		{"GET", path + "/:rev", retrieveRevision(service), "Retrieve a " + parentName + " as it was at one of its revisions", nil, 200, models.Revision{}},
+		{"POST", path + "/:rev/revert", revertRevision(service), "Revert a " + parentName + " to one of its revisions, saving it as a new version", nil, 200, service.model},
+	}
+}
+
+func listRevisions(service revisionService) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		results, err := service.list(callerOf(r), params.ByName("id"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, results)
+	}
+}
+
+func retrieveRevision(service revisionService) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		version, err := parseRevision(params)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		result, err := service.retrieve(callerOf(r), params.ByName("id"), version)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+	}
+}
+
+func revertRevision(service revisionService) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// Make sure we're changing the version the client expects, if they told us which one.
+		expectedVersion, err := common.ParseIfMatch(r.Header.Get("If-Match"))
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		version, err := parseRevision(params)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		result, err := service.revert(callerOf(r), params.ByName("id"), version, expectedVersion)
+		if err != nil {
+			common.WriteErrorResponse(w, err)
+			return
+		}
+		common.WriteResponse(w, 200, result)
+	}
+}
+
+// Revisions are numbered by version, so anything else can't be one.
+func parseRevision(params httprouter.Params) (int, error) {
+	version, err := strconv.Atoi(params.ByName("rev"))
+	if err != nil || version < 1 {
+		return 0, common.NotFoundErr
+	}
+	return version, nil
+}
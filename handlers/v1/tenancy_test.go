@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/users"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// The prefix for the throwaway databases tests use, so they never touch real data.
+const testDatabasePrefix = "test_"
+
+// Points every service at throwaway databases, or skips the test if there's no Mongo server to use. Call what it returns when the test is done, to drop them.
+func useTestDatabases(t *testing.T) func() {
+	if err := common.PingMongo(); err != nil {
+		t.Skipf("Mongo isn't available: %s", err.Error())
+	}
+	cfg := config.GetConfig()
+	original := cfg.DatabasePrefix
+	cfg.DatabasePrefix = testDatabasePrefix
+	return func() {
+		defer func() { cfg.DatabasePrefix = original }()
+		session, err := common.CopyMongoSession()
+		if err != nil {
+			return
+		}
+		defer session.Close()
+		names, _ := session.DatabaseNames()
+		for _, name := range names {
+			if strings.HasPrefix(name, testDatabasePrefix) {
+				session.DB(name).DropDatabase()
+			}
+		}
+	}
+}
+
+// Registers a user and logs them in, returning their token. Each user is their own tenant.
+func signUp(t *testing.T, name string) string {
+	creds := models.Credentials{Email: name + "-" + time.Now().Format("150405.000000") + "@example.com", Password: "correct horse battery"}
+	_, err := users.Register(creds)
+	assert.Nil(t, err)
+	session, err := users.Login(creds)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return session.Token
+}
+
+// Sends a request through every v1 route, the way the server does, as the user with this token.
+func sendAs(router *httprouter.Router, token, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+	if method == "PATCH" {
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func TestOtherTenantsCantReachDocuments(t *testing.T) {
+	defer useTestDatabases(t)()
+	router := httprouter.New()
+	RegisterHandlers(router)
+	owner, intruder := signUp(t, "owner"), signUp(t, "intruder")
+
+	for _, testCase := range []struct {
+		resource string
+		body     string
+		patch    string
+	}{
+		{"categories", `{"name":"Groceries"}`, `{"name":"Mine now"}`},
+		{"plans", `{"savingsStrategy":"shared"}`, `{"savingsStrategy":"prioritized"}`},
+		{"budgets", `{"startDate":"2018-05-12","endDate":"2018-05-26"}`, `{"endDate":"2018-05-27"}`},
+	} {
+		created := sendAs(router, owner, "POST", "/v1/"+testCase.resource, testCase.body)
+		if !assert.Equal(t, 201, created.Code, "CASE: %s: %s", testCase.resource, created.Body.String()) {
+			continue
+		}
+		var doc struct {
+			ID string `json:"id"`
+		}
+		assert.Nil(t, json.Unmarshal(created.Body.Bytes(), &doc))
+		path := "/v1/" + testCase.resource + "/" + doc.ID
+
+		for _, method := range []string{"GET", "PATCH", "DELETE"} {
+			recorder := sendAs(router, intruder, method, path, testCase.patch)
+			assert.Equal(t, 404, recorder.Code, "CASE: %s %s by another tenant", method, testCase.resource)
+		}
+		listed := sendAs(router, intruder, "GET", "/v1/"+testCase.resource, "")
+		assert.NotContains(t, listed.Body.String(), doc.ID, "CASE: %s listed for another tenant", testCase.resource)
+
+		unchanged := sendAs(router, owner, "GET", path, "")
+		assert.Equal(t, 200, unchanged.Code, "CASE: %s", testCase.resource)
+		assert.Equal(t, created.Header().Get("ETag"), unchanged.Header().Get("ETag"), "CASE: %s was changed by another tenant", testCase.resource)
+	}
+}
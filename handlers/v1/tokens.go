@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/tokens"
+	"github.com/julienschmidt/httprouter"
+)
+
+func listAccessTokens(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	results, err := tokens.List(callerOf(r))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, results)
+}
+
+func createAccessToken(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// Parse the request body.
+	var token models.AccessToken
+	err := common.DecodeStrict(r.Body, &token)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	result, err := tokens.Create(callerOf(r), token)
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 201, result)
+}
+
+func revokeAccessToken(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	err := tokens.Revoke(callerOf(r), params.ByName("id"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 204, nil)
+}
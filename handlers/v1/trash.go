@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/services/trash"
+	"github.com/julienschmidt/httprouter"
+)
+
+func listTrash(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	results, err := trash.List(callerOf(r))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, results)
+}
+
+func restoreFromTrash(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	result, err := trash.Restore(callerOf(r), params.ByName("resource"), params.ByName("id"))
+	if err != nil {
+		common.WriteErrorResponse(w, err)
+		return
+	}
+	common.WriteResponse(w, 200, result)
+}
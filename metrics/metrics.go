@@ -0,0 +1,322 @@
+// Package metrics keeps counters, histograms, and gauges, and writes them in Prometheus's text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit latencies measured in seconds, from a millisecond to ten seconds.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector is anything the registry can write out.
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry holds every metric we report.
+type Registry struct {
+	sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry returns an empty registry. Most code should use Default instead.
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]collector{}}
+}
+
+// Default is the registry the constructors add their metrics to, and that Handler serves.
+var Default = NewRegistry()
+
+func (reg *Registry) register(c collector) {
+	reg.Lock()
+	defer reg.Unlock()
+	if _, ok := reg.collectors[c.name()]; ok {
+		panic("metrics: " + c.name() + " is already registered")
+	}
+	reg.collectors[c.name()] = c
+}
+
+// WriteText writes every metric in Prometheus's text format, ordered by name.
+func (reg *Registry) WriteText(w io.Writer) error {
+	reg.Lock()
+	names := make([]string, 0, len(reg.collectors))
+	for name := range reg.collectors {
+		names = append(names, name)
+	}
+	reg.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		reg.Lock()
+		c := reg.collectors[name]
+		reg.Unlock()
+		err := c.write(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the default registry's metrics, for Prometheus to scrape.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Default.WriteText(w)
+	})
+}
+
+// metric holds what every kind of metric has in common: a name, help text, and the names of its labels.
+type metric struct {
+	sync.Mutex
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (m *metric) name() string {
+	return m.metricName
+}
+
+func (m *metric) writeHeader(w io.Writer, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.metricName, escapeHelp(m.help), m.metricName, kind)
+	return err
+}
+
+// Joins label values into a map key. The values are checked against the labels, since a mismatch is a programming mistake.
+func (m *metric) key(values []string) string {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s needs %d label values, got %d", m.metricName, len(m.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// Formats labels like {route="/v1/plans",status="200"}, including any extra ones, like a histogram's le.
+func (m *metric) labelString(key string, extra ...string) string {
+	pairs := make([]string, 0, len(m.labels)+len(extra)/2)
+	if len(m.labels) > 0 {
+		for idx, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, m.labels[idx]+`="`+escapeLabel(value)+`"`)
+		}
+	}
+	for idx := 0; idx+1 < len(extra); idx += 2 {
+		pairs = append(pairs, extra[idx]+`="`+escapeLabel(extra[idx+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter counts things that only ever go up, like requests, separately for each combination of label values.
+type Counter struct {
+	metric
+	values map[string]float64
+}
+
+// NewCounter adds a counter to the default registry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter adds a counter to the registry.
+func (reg *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{metric: metric{metricName: name, help: help, labels: labels}, values: map[string]float64{}}
+	reg.register(c)
+	return c
+}
+
+// Inc adds one for these label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds the amount for these label values.
+func (c *Counter) Add(amount float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.Lock()
+	defer c.Unlock()
+	c.values[key] += amount
+}
+
+// Value returns the count for these label values. It's mostly useful for tests.
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.Lock()
+	defer c.Unlock()
+	return c.values[key]
+}
+
+func (c *Counter) write(w io.Writer) error {
+	c.Lock()
+	defer c.Unlock()
+	err := c.writeHeader(w, "counter")
+	if err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(c.values) {
+		_, err = fmt.Fprintf(w, "%s%s %s\n", c.metricName, c.labelString(key), formatValue(c.values[key]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram sorts observations, like how long requests take, into buckets, separately for each combination of label values.
+type Histogram struct {
+	metric
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram adds a histogram with these (ascending) bucket upper bounds to the default registry.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram adds a histogram with these (ascending) bucket upper bounds to the registry.
+func (reg *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{metric: metric{metricName: name, help: help, labels: labels}, buckets: buckets, series: map[string]*histogramSeries{}}
+	reg.register(h)
+	return h
+}
+
+// Observe records a value for these label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.Lock()
+	defer h.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for idx, bound := range h.buckets {
+		if value <= bound {
+			s.counts[idx]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count returns how many values were observed for these label values. It's mostly useful for tests.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+	h.Lock()
+	defer h.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.Lock()
+	defer h.Unlock()
+	err := h.writeHeader(w, "histogram")
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		for idx, bound := range h.buckets {
+			_, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelString(key, "le", formatValue(bound)), s.counts[idx])
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.metricName, h.labelString(key, "le", "+Inf"), s.count,
+			h.metricName, h.labelString(key), formatValue(s.sum),
+			h.metricName, h.labelString(key), s.count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GaugeFunc reports a value that can go up or down, like how many budgets there are, by asking for it each time metrics are collected.
+type GaugeFunc struct {
+	metric
+	value func() (float64, error)
+}
+
+// NewGaugeFunc adds a gauge to the default registry. If value fails or panics, the gauge is left out rather than reported wrong.
+func NewGaugeFunc(name, help string, value func() (float64, error)) *GaugeFunc {
+	return Default.NewGaugeFunc(name, help, value)
+}
+
+// NewGaugeFunc adds a gauge to the registry.
+func (reg *Registry) NewGaugeFunc(name, help string, value func() (float64, error)) *GaugeFunc {
+	g := &GaugeFunc{metric: metric{metricName: name, help: help}, value: value}
+	reg.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) error {
+	value, err := g.collect()
+	if err != nil {
+		return nil
+	}
+	err = g.writeHeader(w, "gauge")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s %s\n", g.metricName, formatValue(value))
+	return err
+}
+
+// Asks for the gauge's value, treating a panic as a failure, so the other metrics are still reported.
+func (g *GaugeFunc) collect() (value float64, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("metrics: %s panicked: %v", g.metricName, p)
+		}
+	}()
+	return g.value()
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func text(t *testing.T, reg *Registry) string {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, reg.WriteText(buf))
+	return buf.String()
+}
+
+func TestCounter(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.NewCounter("requests_total", "How many requests.", "route", "status")
+	c.Inc("/v1/plans", "200")
+	c.Inc("/v1/plans", "200")
+	c.Add(3, `/v1/"odd"`, "500")
+	assert.Equal(t, 2.0, c.Value("/v1/plans", "200"))
+	assert.Equal(t, 0.0, c.Value("/v1/plans", "404"))
+
+	assert.Equal(t, `# HELP requests_total How many requests.
+# TYPE requests_total counter
+requests_total{route="/v1/\"odd\"",status="500"} 3
+requests_total{route="/v1/plans",status="200"} 2
+`, text(t, reg))
+}
+
+func TestCounterChecksLabels(t *testing.T) {
+	c := NewRegistry().NewCounter("requests_total", "How many requests.", "route")
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { c.Inc("a", "b") })
+}
+
+func TestHistogram(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("duration_seconds", "How long.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(5, "/a")
+	assert.Equal(t, uint64(3), h.Count("/a"))
+	assert.Equal(t, uint64(0), h.Count("/b"))
+
+	assert.Equal(t, `# HELP duration_seconds How long.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a",le="0.1"} 1
+duration_seconds_bucket{route="/a",le="1"} 2
+duration_seconds_bucket{route="/a",le="+Inf"} 3
+duration_seconds_sum{route="/a"} 5.55
+duration_seconds_count{route="/a"} 3
+`, text(t, reg))
+}
+
+func TestGaugeFunc(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewGaugeFunc("budgets", "How many budgets.", func() (float64, error) { return 7, nil })
+	reg.NewGaugeFunc("plans", "How many plans.", func() (float64, error) { return 0, errors.New("no database") })
+	reg.NewGaugeFunc("users", "How many users.", func() (float64, error) { panic("can't connect") })
+
+	assert.Equal(t, "# HELP budgets How many budgets.\n# TYPE budgets gauge\nbudgets 7\n", text(t, reg), "gauges that fail should be left out")
+}
+
+func TestRegisterTwice(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("requests_total", "How many requests.")
+	assert.Panics(t, func() { reg.NewCounter("requests_total", "Again.") })
+}
+
+func TestHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// AccessTokenPrefix starts every access token, so they can't be mistaken for the tokens people get for logging in.
+const AccessTokenPrefix = "zbb_pat_"
+
+// ScopedResources are the kinds of resource an access token can be given scopes for.
+var ScopedResources = []string{"categories", "plans", "budgets"}
+
+// Scopes lists every scope an access token can have: reading or writing each kind of resource.
+var Scopes = func() []string {
+	scopes := make([]string, 0, 2*len(ScopedResources))
+	for _, resource := range ScopedResources {
+		scopes = append(scopes, resource+":read", resource+":write")
+	}
+	return scopes
+}()
+
+// IsScope says whether this is one of the scopes we know about.
+func IsScope(scope string) bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessToken lets a script or integration act on a user's behalf, limited to its scopes, until it expires or is revoked. Only a hash of the token itself is stored.
+type AccessToken struct {
+	ID       SafeUUID   `json:"id" bson:"_id" readonly:"true"`
+	Name     string     `json:"name"`
+	Scopes   []string   `json:"scopes"`
+	Expires  time.Time  `json:"expires"`
+	LastUsed *time.Time `json:"lastUsed,omitempty" bson:"lastUsed,omitempty" readonly:"true"`
+	UserID   SafeUUID   `json:"-" bson:"userId"`
+	Hash     string     `json:"-" bson:"hash"`
+	Timestamped
+}
+
+// NewAccessToken is what someone gets when they create an access token: the token itself, which is never shown again, and its details.
+type NewAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+// GetValidated returns a sanitized copy if the token has a name, only known scopes, and an expiry in the future; otherwise, it returns an error.
+func (token AccessToken) GetValidated() (AccessToken, error) {
+	errs := make([]error, 0)
+	token.Name = strings.TrimSpace(token.Name)
+	if common.StringIsEmpty(token.Name) {
+		errs = append(errs, common.NewValidationError("name", common.RequiredTextCode, "name"))
+	}
+
+	scopes := make([]string, 0, len(token.Scopes))
+	seen := map[string]bool{}
+	for idx, scope := range token.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !IsScope(scope) {
+			errs = append(errs, common.NewValidationError("scopes."+strconv.Itoa(idx), common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(Scopes, ", "))))
+		} else if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(token.Scopes) == 0 {
+		errs = append(errs, common.NewValidationError("scopes", common.MissingCode, "scopes"))
+	}
+
+	if token.Expires.IsZero() {
+		errs = append(errs, common.NewValidationError("expires", common.MissingCode, "expires"))
+	} else if !token.Expires.After(time.Now()) {
+		errs = append(errs, common.NewValidationError("expires", common.NumOutOfRangeCode, "future"))
+	}
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	token.Scopes = scopes
+	return token, nil
+}
+
+// IsExpired says whether the token has expired as of now.
+func (token AccessToken) IsExpired(now time.Time) bool {
+	return !now.Before(token.Expires)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenGetValidated(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	validated, err := AccessToken{Name: " cron ", Scopes: []string{"plans:read", " plans:read", "budgets:write"}, Expires: tomorrow}.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, "cron", validated.Name)
+	assert.Equal(t, []string{"plans:read", "budgets:write"}, validated.Scopes, "duplicate scopes should be dropped")
+
+	for _, testCase := range []struct {
+		token         AccessToken
+		expectedCodes map[string]string
+	}{
+		{AccessToken{}, map[string]string{"name": "REQUIRED_TEXT", "scopes": common.MissingCode, "expires": common.MissingCode}},
+		{AccessToken{Name: "x", Scopes: []string{"plans:read", "admin"}, Expires: tomorrow}, map[string]string{"scopes.1": common.BadEnumChoiceCode}},
+		{AccessToken{Name: "x", Scopes: []string{"plans:read"}, Expires: time.Now().Add(-time.Minute)}, map[string]string{"expires": common.NumOutOfRangeCode}},
+	} {
+		_, err := testCase.token.GetValidated()
+		validationErr, ok := common.GetValidationError(err)
+		if !assert.True(t, ok, "CASE: %+v, expected a validation error", testCase.token) {
+			continue
+		}
+		codes := map[string]string{}
+		for _, field := range validationErr.Fields {
+			codes[field.FieldName] = field.Code
+		}
+		assert.Equal(t, testCase.expectedCodes, codes, "CASE: %+v", testCase.token)
+	}
+}
+
+func TestAccessTokenIsExpired(t *testing.T) {
+	now := time.Now()
+	token := AccessToken{Expires: now}
+	assert.True(t, token.IsExpired(now))
+	assert.False(t, token.IsExpired(now.Add(-time.Second)))
+}
@@ -10,7 +10,7 @@ type Amount struct {
 // GetValidated returns a sanitized copy, or an error if something isn't right.
 func (a Amount) GetValidated() (Amount, error) {
 	if a.AmountCents < 0 {
-		return Amount{}, common.NewValidationError("amount", common.NumOutOfRangeCode, "The amount cannot be negative.")
+		return Amount{}, common.NewValidationError("amount", common.NumOutOfRangeCode, "negative")
 	}
 	return a, nil
 }
@@ -0,0 +1,18 @@
+package models
+
+// Attributed records which users created and last modified a document, alongside Timestamped's when.
+type Attributed struct {
+	CreatedBy  string `json:"createdBy,omitempty" bson:"createdBy,omitempty" readonly:"true"`
+	ModifiedBy string `json:"modifiedBy,omitempty" bson:"modifiedBy,omitempty" readonly:"true"`
+}
+
+// SetCreator records the user as both creator and last modifier. This is useful when a composing model is first created.
+func (a *Attributed) SetCreator(userID string) {
+	a.CreatedBy = userID
+	a.ModifiedBy = userID
+}
+
+// SetModifier records the user as the last modifier. This is useful when a composing model is updated.
+func (a *Attributed) SetModifier(userID string) {
+	a.ModifiedBy = userID
+}
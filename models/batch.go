@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// MaxBatchOperations caps how much work a single batch can ask for.
+const MaxBatchOperations = 100
+
+// BatchRequest is an ordered list of operations to perform in one request. If Atomic is set, either every operation succeeds or none of them take effect.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// GetValidated returns a sanitized copy if every operation is well-formed; otherwise, it returns an error.
+func (req BatchRequest) GetValidated() (BatchRequest, error) {
+	if len(req.Operations) == 0 {
+		return BatchRequest{}, common.NewValidationError("operations", common.MissingCode, "operations")
+	}
+	if len(req.Operations) > MaxBatchOperations {
+		return BatchRequest{}, common.NewValidationError("operations", common.TooManyCode, "operations", common.Arg("max", MaxBatchOperations))
+	}
+
+	errs := make([]error, 0)
+	for idx, op := range req.Operations {
+		var opErr error
+		req.Operations[idx], opErr = op.GetValidated()
+		errs = append(errs, common.AddValidationContext(opErr, "operations."+strconv.Itoa(idx)))
+	}
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return BatchRequest{}, err
+	}
+	return req, nil
+}
+
+// BatchOperation creates, updates, or deletes a single resource. Updates and deletes may include the version they expect to change, like an If-Match header would.
+type BatchOperation struct {
+	Op       string          `json:"op"`
+	Resource string          `json:"resource"`
+	ID       string          `json:"id,omitempty"`
+	IfMatch  string          `json:"ifMatch,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// These are the operations a batch can perform.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOps lists each operation a batch can perform.
+var BatchOps = []string{BatchCreate, BatchUpdate, BatchDelete}
+
+// BatchResources lists each resource a batch can operate on.
+var BatchResources = []string{"categories", "plans", "budgets"}
+
+// GetValidated returns a sanitized copy if the operation, resource, ID, and body make sense together; otherwise, it returns an error.
+func (op BatchOperation) GetValidated() (BatchOperation, error) {
+	errs := make([]error, 0)
+	if !isOneOf(op.Op, BatchOps) {
+		errs = append(errs, common.NewValidationError("op", common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(BatchOps, ", "))))
+	}
+	if !isOneOf(op.Resource, BatchResources) {
+		errs = append(errs, common.NewValidationError("resource", common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(BatchResources, ", "))))
+	}
+	if (op.Op == BatchUpdate || op.Op == BatchDelete) && common.StringIsEmpty(op.ID) {
+		errs = append(errs, common.NewValidationError("id", common.MissingCode, "operationId", common.Arg("op", op.Op)))
+	}
+	if (op.Op == BatchCreate || op.Op == BatchUpdate) && len(op.Body) == 0 {
+		errs = append(errs, common.NewValidationError("body", common.MissingCode, "operationBody", common.Arg("op", op.Op)))
+	}
+	if _, ifMatchErr := common.ParseIfMatch(op.IfMatch); ifMatchErr != nil {
+		errs = append(errs, common.NewValidationError("ifMatch", common.BadETagCode, ""))
+	}
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return BatchOperation{}, err
+	}
+	op.ID = strings.TrimSpace(op.ID)
+	return op, nil
+}
+
+// BatchResponse reports what happened with each operation, in the same order they were requested.
+type BatchResponse struct {
+	Results    []BatchResult `json:"results"`
+	RolledBack bool          `json:"rolledBack"`
+}
+
+// BatchResult is the status and body each operation would have gotten as its own request.
+type BatchResult struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+func isOneOf(input string, choices []string) bool {
+	for _, choice := range choices {
+		if input == choice {
+			return true
+		}
+	}
+	return false
+}
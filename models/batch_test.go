@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchRequestGetValidated(t *testing.T) {
+	req := BatchRequest{
+		Operations: []BatchOperation{
+			{Op: BatchCreate, Resource: "plans", Body: json.RawMessage(`{}`)},
+			{Op: BatchUpdate, Resource: "budgets", ID: " abc ", IfMatch: `"2"`, Body: json.RawMessage(`{}`)},
+			{Op: BatchDelete, Resource: "categories", ID: "abc"},
+		},
+	}
+	validated, err := req.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", validated.Operations[1].ID)
+}
+
+func TestBatchRequestGetValidatedEmpty(t *testing.T) {
+	_, err := BatchRequest{}.GetValidated()
+	assert.Equal(t, common.NewValidationError("operations", common.MissingCode, "operations"), err)
+}
+
+func TestBatchRequestGetValidatedBadOperations(t *testing.T) {
+	req := BatchRequest{
+		Operations: []BatchOperation{
+			{Op: "upsert", Resource: "plans", Body: json.RawMessage(`{}`)},
+			{Op: BatchUpdate, Resource: "widgets", IfMatch: "3"},
+		},
+	}
+	_, err := req.GetValidated()
+	validationErr, ok := common.GetValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a validation error, got %#v", err)
+	}
+	fieldNames := []string{}
+	for _, field := range validationErr.Fields {
+		fieldNames = append(fieldNames, field.FieldName)
+	}
+	assert.Equal(t, []string{
+		"operations.0.op",
+		"operations.1.resource",
+		"operations.1.id",
+		"operations.1.body",
+		"operations.1.ifMatch",
+	}, fieldNames)
+}
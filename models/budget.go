@@ -1,22 +1,31 @@
 package models
 
 import (
+	"strings"
+
 	"github.com/hjkelly/zbbapi/common"
 )
 
 type Budget struct {
-	ID        SafeUUID        `json:"id" bson:"_id"`
+	ID        SafeUUID        `json:"id" bson:"_id" readonly:"true"`
 	StartDate common.Date     `json:"startDate"`
 	EndDate   common.Date     `json:"endDate"`
 	Incomes   NamesAndAmounts `json:"incomes"`
-	Bills     NamesAndAmounts `json:"bills"`
+	Bills     BudgetBills     `json:"bills"`
 	Expenses  NamesAndAmounts `json:"expenses"`
 	Savings   NamesAndAmounts `json:"savings"`
-	Checklist []ChecklistItem `json:"checklist"`
-	Balance   Amount
+	Checklist Checklist       `json:"checklist"`
+	Balance   Amount          `readonly:"true"`
+	Totals    *SectionTotals  `json:"totals,omitempty" bson:"-" readonly:"true"`
 	Timestamped
+	Attributed `bson:",inline"`
+	Versioned  `bson:",inline"`
+	Deletable  `bson:",inline"`
+	Owned      `bson:",inline"`
+	Warned     `bson:",inline"`
 }
 
+// GetValidated returns a sanitized copy with its balance and totals worked out if the dates, line items, and checklist are properly defined; otherwise, it returns an error. The copy is warned if the balance is negative, which is allowed but means the budget doesn't add up.
 func (budget Budget) GetValidated() (Budget, error) {
 	// this will hold each error as we validate
 	var err error
@@ -32,34 +41,35 @@ func (budget Budget) GetValidated() (Budget, error) {
 	errs = append(errs, common.AddValidationContext(err, "incomes"))
 	budget.Expenses, err = budget.Expenses.GetValidated()
 	errs = append(errs, common.AddValidationContext(err, "expenses"))
-	budget.Bills, err = budget.Bills.GetValidated()
-	errs = append(errs, common.AddValidationContext(err, "bills"))
+	var billsErr error
+	budget.Bills, billsErr = budget.Bills.GetValidated()
+	errs = append(errs, common.AddValidationContext(billsErr, "bills"))
 	budget.Savings, err = budget.Savings.GetValidated()
 	errs = append(errs, common.AddValidationContext(err, "savings"))
 
-	// checklist items
-	for i, item := range budget.Checklist {
-		budget.Checklist[i], err = item.GetValidated()
-		errs = append(errs, err)
+	// checklist items: they can refer to bills, so only check those references if the bills themselves made sense.
+	if billsErr == nil {
+		budget.Checklist = budget.Checklist.withManualBills(budget.Bills)
 	}
+	budget.Checklist, err = budget.Checklist.GetValidated(budget.Bills, billsErr == nil)
+	errs = append(errs, common.AddValidationContext(err, "checklist"))
 
-	// TODO: make sure they didn't try to provide read-only/protected fields
-
-	// Calculate the balance.
-	balance := 0
+	// Add up each section, and what's left once everything's paid for.
+	incomes, bills, expenses, savings := 0, 0, 0, 0
 	for _, income := range budget.Incomes {
-		balance += income.Amount.AmountCents
+		incomes += income.Amount.AmountCents
 	}
 	for _, expense := range budget.Expenses {
-		balance -= expense.Amount.AmountCents
+		expenses += expense.Amount.AmountCents
 	}
 	for _, bill := range budget.Bills {
-		balance -= bill.Amount.AmountCents
+		bills += bill.Amount.AmountCents
 	}
 	for _, saving := range budget.Savings {
-		balance -= saving.Amount.AmountCents
+		savings += saving.Amount.AmountCents
 	}
-	budget.Balance.AmountCents = balance
+	budget.Totals = newSectionTotals(incomes, bills, expenses, savings)
+	budget.Balance = budget.Totals.Balance
 
 	// Finalize the errors, if there were any.
 	err = common.CombineErrors(errs...)
@@ -67,16 +77,186 @@ func (budget Budget) GetValidated() (Budget, error) {
 		return Budget{}, err
 	}
 
-	// Otherwise, add any sanitized values.
+	// Otherwise, warn about anything that doesn't add up and return the sanitized values.
+	budget.Warnings = nil
+	if budget.Balance.AmountCents < 0 {
+		budget.Warn(common.NewWarning("Balance", common.NegativeBalanceCode, ""))
+	}
 	return budget, nil
 }
 
+// LineItems points at every line item in the budget, so they can be edited in place (e.g. to reassign or expand their categories).
+func (budget *Budget) LineItems() []LineItemRef {
+	refs := make([]LineItemRef, 0)
+	for idx := range budget.Incomes {
+		refs = append(refs, lineItemRef("incomes", &budget.Incomes[idx]))
+	}
+	for idx := range budget.Bills {
+		refs = append(refs, lineItemRef("bills", &budget.Bills[idx].NameAndAmount))
+	}
+	for idx := range budget.Expenses {
+		refs = append(refs, lineItemRef("expenses", &budget.Expenses[idx]))
+	}
+	for idx := range budget.Savings {
+		refs = append(refs, lineItemRef("savings", &budget.Savings[idx]))
+	}
+	return refs
+}
+
+// BudgetBill is a bill to be paid within the budget's period. Bills used to be plain NamesAndAmounts, so they're still stored flat.
+type BudgetBill struct {
+	NameAndAmount       `bson:",inline"`
+	IsPaidAutomatically bool `json:"isPaidAutomatically"`
+}
+
+// GetValidated returns a sanitized copy if the name and amount are properly defined; otherwise, it returns an error.
+func (bill BudgetBill) GetValidated() (BudgetBill, error) {
+	cleanCRAM, cramErr := bill.NameAndAmount.GetValidated()
+	if cramErr != nil {
+		return BudgetBill{}, cramErr
+	}
+	bill.NameAndAmount = cleanCRAM
+	return bill, nil
+}
+
+// BudgetBills groups several bills together in a way that makes validation easier.
+type BudgetBills []BudgetBill
+
+// GetValidated returns a sanitized copy if all bills are properly defined; otherwise, it returns an error.
+func (bills BudgetBills) GetValidated() (BudgetBills, error) {
+	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(bills))
+	for idx, bill := range bills {
+		var billErr error
+		bill.NameAndAmount = bill.NameAndAmount.withID()
+		bills[idx], billErr = bill.GetValidated()
+		errs = append(errs, common.AddValidationContext(billErr, bill.contextName(idx)))
+		lineItems = append(lineItems, bill.NameAndAmount)
+	}
+	errs = append(errs, validateUniqueIDs(lineItems))
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return BudgetBills{}, err
+	}
+
+	return bills, nil
+}
+
+// find returns the bill with this ID, if there is one.
+func (bills BudgetBills) find(id SafeUUID) (BudgetBill, bool) {
+	for _, bill := range bills {
+		if bill.ID == id {
+			return bill, true
+		}
+	}
+	return BudgetBill{}, false
+}
+
+// ChecklistItem is something to do during the budget's period, like paying a bill by hand. Items marked Automatic were added for a bill that isn't paid automatically; they're kept in step with that bill and removed when it no longer needs paying by hand.
 type ChecklistItem struct {
-	Name      string `json:"name"`
-	Completed bool   `json:"completed"`
+	ID        SafeUUID     `json:"id"`
+	Name      string       `json:"name"`
+	Completed bool         `json:"completed"`
+	DueDate   *common.Date `json:"dueDate,omitempty"`
+	BillID    SafeUUID     `json:"billId,omitempty"`
+	Automatic bool         `json:"automatic"`
 }
 
+// GetValidated returns a sanitized copy if the item has a name and its optional ID and due date make sense; otherwise, it returns an error.
 func (item ChecklistItem) GetValidated() (ChecklistItem, error) {
-	// TODO
+	errs := make([]error, 0)
+
+	cleanID, idErr := item.ID.GetValidated()
+	errs = append(errs, common.AddValidationContext(idErr, "id"))
+	cleanName := strings.TrimSpace(item.Name)
+	if len(cleanName) == 0 {
+		errs = append(errs, common.NewValidationError("name", common.MissingCode, "name"))
+	}
+	if item.DueDate != nil {
+		errs = append(errs, common.AddValidationContext(item.DueDate.ValidateNonZero(), "dueDate"))
+	}
+	var cleanBillID SafeUUID
+	var billIDErr error
+	if len(item.BillID) > 0 {
+		cleanBillID, billIDErr = item.BillID.GetValidated()
+		errs = append(errs, common.AddValidationContext(billIDErr, "billId"))
+	}
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return ChecklistItem{}, err
+	}
+
+	item.ID = cleanID
+	item.Name = cleanName
+	item.BillID = cleanBillID
 	return item, nil
 }
+
+// Checklist is the list of things to do during the budget's period.
+type Checklist []ChecklistItem
+
+// GetValidated returns a sanitized copy if all items are properly defined; otherwise, it returns an error. If checkBills is set, any item linked to a bill must link to one of these bills.
+func (checklist Checklist) GetValidated(bills BudgetBills, checkBills bool) (Checklist, error) {
+	errs := make([]error, 0)
+	for idx, item := range checklist {
+		item.ID = ensureID(item.ID)
+		cleanItem, itemErr := item.GetValidated()
+		if itemErr == nil && checkBills && len(cleanItem.BillID) > 0 {
+			if _, ok := bills.find(cleanItem.BillID); !ok {
+				itemErr = common.NewValidationError("billId", common.NonexistentRefCode, "bill")
+			}
+		}
+		checklist[idx] = cleanItem
+		errs = append(errs, common.AddValidationContext(itemErr, idOrIndex(item.ID, idx)))
+	}
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return Checklist{}, err
+	}
+	return checklist, nil
+}
+
+// UnlinkBill returns a copy of the checklist where no item refers to this bill any longer, so the bill can be removed. Automatic items for it are dropped during validation.
+func (checklist Checklist) UnlinkBill(billID SafeUUID) Checklist {
+	result := make(Checklist, 0, len(checklist))
+	for _, item := range checklist {
+		if !item.Automatic && strings.EqualFold(string(item.BillID), string(billID)) {
+			item.BillID = ""
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// withManualBills returns a copy of the checklist with an automatic item for each bill that has to be paid by hand. Automatic items for bills that are gone or are now paid automatically are dropped, and the rest take on their bill's current name.
+func (checklist Checklist) withManualBills(bills BudgetBills) Checklist {
+	result := make(Checklist, 0, len(checklist)+len(bills))
+	covered := map[SafeUUID]bool{}
+	for _, item := range checklist {
+		if len(item.BillID) > 0 {
+			covered[item.BillID] = true
+		}
+		if item.Automatic {
+			bill, ok := bills.find(item.BillID)
+			if !ok || bill.IsPaidAutomatically {
+				continue
+			}
+			item.Name = bill.Name
+		}
+		result = append(result, item)
+	}
+	for _, bill := range bills {
+		if bill.IsPaidAutomatically || covered[bill.ID] {
+			continue
+		}
+		result = append(result, ChecklistItem{
+			Name:      bill.Name,
+			BillID:    bill.ID,
+			Automatic: true,
+		})
+	}
+	return result
+}
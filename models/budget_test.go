@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func TestBudgetGetValidatedMinimal(t *testing.T) {
@@ -28,11 +30,11 @@ func TestBudgetGetValidated(t *testing.T) {
 			{Name: "X", Amount: Amount{AmountCents: 111111}},
 			{Name: "X", Amount: Amount{AmountCents: 222222}},
 		},
-		Bills: NamesAndAmounts{
-			{Name: "X", Amount: Amount{AmountCents: 33333}},
-			{Name: "X", Amount: Amount{AmountCents: 44444}},
-			{Name: "X", Amount: Amount{AmountCents: 5555}},
-			{Name: "X", Amount: Amount{AmountCents: 6666}},
+		Bills: BudgetBills{
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 33333}}},
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 44444}}},
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 5555}}},
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 6666}}},
 		},
 		Savings: NamesAndAmounts{
 			{Name: "X", Amount: Amount{AmountCents: 7777}},
@@ -50,16 +52,24 @@ func TestBudgetGetValidated(t *testing.T) {
 	if validated.Balance.AmountCents != expectedBalance {
 		t.Errorf("Didn't get the expected balance of %d; instead, got: %d", expectedBalance, validated.Balance.AmountCents)
 	}
+	assert.Equal(t, &SectionTotals{
+		Incomes:  Amount{AmountCents: 111111 + 222222},
+		Bills:    Amount{AmountCents: 33333 + 44444 + 5555 + 6666},
+		Expenses: Amount{AmountCents: 88888 + 9999},
+		Savings:  Amount{AmountCents: 7777},
+		Balance:  validated.Balance,
+	}, validated.Totals)
+	assert.Empty(t, validated.Warnings)
 }
 
 func TestBudgetGetValidatedNegativeAmount(t *testing.T) {
 	b := Budget{
 		StartDate: common.Date{Year: 2018, Month: 5, Day: 12},
 		EndDate:   common.Date{Year: 2018, Month: 5, Day: 26},
-		Bills: NamesAndAmounts{
-			{Name: "X", Amount: Amount{AmountCents: 1}},
-			{Name: "X", Amount: Amount{AmountCents: 1}},
-			{Name: "X", Amount: Amount{AmountCents: 1}},
+		Bills: BudgetBills{
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 1}}},
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 1}}},
+			{NameAndAmount: NameAndAmount{Name: "X", Amount: Amount{AmountCents: 1}}},
 		},
 		Savings: NamesAndAmounts{
 			{Name: "X", Amount: Amount{AmountCents: 1}},
@@ -77,4 +87,93 @@ func TestBudgetGetValidatedNegativeAmount(t *testing.T) {
 	if validated.Balance.AmountCents != expectedBalance {
 		t.Errorf("Didn't get the expected balance of %d; instead, got: %d", expectedBalance, validated.Balance.AmountCents)
 	}
+	assert.Equal(t, []common.InvalidField{common.NewWarning("Balance", common.NegativeBalanceCode, "")}, validated.Warnings)
+}
+
+func TestBudgetGetValidatedChecklistFromManualBills(t *testing.T) {
+	manualID := NewSafeUUID()
+	goneID := NewSafeUUID()
+	b := Budget{
+		StartDate: common.Date{Year: 2018, Month: 5, Day: 12},
+		EndDate:   common.Date{Year: 2018, Month: 5, Day: 26},
+		Bills: BudgetBills{
+			{NameAndAmount: NameAndAmount{ID: manualID, Name: "Rent", Amount: Amount{AmountCents: 100}}},
+			{NameAndAmount: NameAndAmount{Name: "Power", Amount: Amount{AmountCents: 100}}, IsPaidAutomatically: true},
+		},
+		Checklist: Checklist{
+			{Name: "Call the bank"},
+			{Name: "Old name", BillID: manualID, Automatic: true},
+			{Name: "Water", BillID: goneID, Automatic: true},
+		},
+	}
+	validated, err := b.GetValidated()
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.Len(t, validated.Checklist, 2) {
+		assert.Equal(t, "Call the bank", validated.Checklist[0].Name)
+		assert.Equal(t, "Rent", validated.Checklist[1].Name, "automatic items should follow their bill's name")
+		assert.Equal(t, manualID, validated.Checklist[1].BillID)
+	}
+
+	// A new manual bill gets an item of its own.
+	validated.Bills = append(validated.Bills, BudgetBill{NameAndAmount: NameAndAmount{Name: "Phone", Amount: Amount{AmountCents: 100}}})
+	revalidated, err := validated.GetValidated()
+	if assert.Nil(t, err) && assert.Len(t, revalidated.Checklist, 3) {
+		assert.Equal(t, ChecklistItem{ID: revalidated.Checklist[2].ID, Name: "Phone", BillID: revalidated.Bills[2].ID, Automatic: true}, revalidated.Checklist[2])
+		_, idErr := revalidated.Checklist[2].ID.GetValidated()
+		assert.Nil(t, idErr, "generated items should be assigned an ID")
+	}
+}
+
+func TestChecklistGetValidatedErrors(t *testing.T) {
+	id := NewSafeUUID()
+	missingBillID := NewSafeUUID()
+	checklist := Checklist{
+		{ID: id, Name: " "},
+		{Name: "Pay rent", BillID: missingBillID},
+		{Name: "Pay power", DueDate: &common.Date{Year: 2018, Month: 2, Day: 30}},
+	}
+	_, err := checklist.GetValidated(BudgetBills{}, true)
+	validationErr, ok := common.GetValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a validation error, got %#v", err)
+	}
+	codes := map[string]string{}
+	for _, field := range validationErr.Fields {
+		codes[field.FieldName] = field.Code
+	}
+	assert.Equal(t, common.MissingCode, codes[string(id)+".name"])
+	assert.Equal(t, common.NonexistentRefCode, codes[string(checklist[1].ID)+".billId"])
+	assert.Len(t, codes, 3)
+}
+
+func TestChecklistUnlinkBill(t *testing.T) {
+	billID := NewSafeUUID()
+	checklist := Checklist{
+		{Name: "Pay rent early", BillID: billID},
+		{Name: "Rent", BillID: billID, Automatic: true},
+	}
+	unlinked := checklist.UnlinkBill(billID)
+	assert.Equal(t, SafeUUID(""), unlinked[0].BillID)
+	assert.Equal(t, billID, unlinked[1].BillID)
+}
+
+func TestBudgetBillsAreStoredFlat(t *testing.T) {
+	// Bills saved before they could be paid automatically look just like incomes.
+	stored, err := bson.Marshal(bson.M{
+		"bills": []bson.M{{"id": "a", "name": "Rent", "categoryid": "c", "amount": bson.M{"amountcents": 1000}}},
+	})
+	assert.Nil(t, err)
+	budget := Budget{}
+	assert.Nil(t, bson.Unmarshal(stored, &budget))
+	assert.Equal(t, BudgetBills{{NameAndAmount: NameAndAmount{ID: "a", Name: "Rent", CategoryID: "c", Amount: Amount{AmountCents: 1000}}}}, budget.Bills)
+
+	data, err := bson.Marshal(budget)
+	assert.Nil(t, err)
+	resaved := bson.M{}
+	assert.Nil(t, bson.Unmarshal(data, &resaved))
+	bill := resaved["bills"].([]interface{})[0].(bson.M)
+	assert.Equal(t, "Rent", bill["name"])
+	assert.NotContains(t, bill, "nameandamount")
 }
@@ -8,45 +8,123 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-// Category lets you build an expected budget and categorize your actual expenses.
+// Category lets you build an expected budget and categorize your actual expenses. Categories can be grouped under a parent category (e.g. "Rent" under "Housing"), and siblings are listed in order of SortOrder.
 type Category struct {
-	ID   uuid.UUID `json:"id" bson:"_id"`
-	Name string    `json:"name"`
+	ID        uuid.UUID `json:"id" bson:"_id" readonly:"true"`
+	Name      string    `json:"name"`
+	ParentID  SafeUUID  `json:"parentId,omitempty"`
+	SortOrder int       `json:"sortOrder"`
 	Timestamped
+	Versioned `bson:",inline"`
+	Deletable `bson:",inline"`
+	Owned     `bson:",inline"`
 }
 
+// SafeID returns the category's ID in the form line items and other categories use to refer to it.
+func (category Category) SafeID() SafeUUID {
+	return SafeUUID(category.ID.String())
+}
+
+// NameAndAmount is the basis for every line item in plans and budgets. Each one gets a server-assigned ID so it can be addressed directly, even as other items are added or removed.
 type NameAndAmount struct {
-	Name string `json:"name"`
+	ID         SafeUUID  `json:"id"`
+	Name       string    `json:"name"`
+	CategoryID SafeUUID  `json:"categoryId,omitempty"`
+	Category   *Category `json:"category,omitempty" bson:"-" readonly:"true"`
 	Amount
 }
 
 func (cram NameAndAmount) GetValidated() (NameAndAmount, error) {
 	var nameErr error
+	cleanID, idErr := cram.ID.GetValidated()
 	cleanName := strings.TrimSpace(cram.Name)
 	if len(cleanName) == 0 {
-		nameErr = common.NewValidationError("name", common.MissingCode, "You must priovide a name.")
+		nameErr = common.NewValidationError("name", common.MissingCode, "name")
 	}
 	cleanAmount, amountErr := cram.Amount.GetValidated()
+	var cleanCategoryID SafeUUID
+	var categoryIDErr error
+	if len(cram.CategoryID) > 0 {
+		cleanCategoryID, categoryIDErr = cram.CategoryID.GetValidated()
+	}
 
-	err := common.CombineErrors(nameErr, amountErr)
+	err := common.CombineErrors(common.AddValidationContext(idErr, "id"), nameErr, common.AddValidationContext(categoryIDErr, "categoryId"), amountErr)
 	if err != nil {
 		return NameAndAmount{}, err
 	}
 
+	cram.ID = cleanID
 	cram.Name = cleanName
+	cram.CategoryID = cleanCategoryID
 	cram.Amount = cleanAmount
 	return cram, nil
 }
 
+// LineItemRef points at one line item within a plan or budget, along with the field name its validation errors should use (e.g. "bills.<id>"). It's only used internally, never sent to clients.
+type LineItemRef struct {
+	Section   string         `json:"-"`
+	FieldName string         `json:"-"`
+	Item      *NameAndAmount `json:"-"`
+}
+
+func lineItemRef(section string, item *NameAndAmount) LineItemRef {
+	return LineItemRef{Section: section, FieldName: section + "." + string(item.ID), Item: item}
+}
+
+// withID returns a copy with a newly assigned ID if the client didn't provide one (i.e. it's a new line item).
+func (cram NameAndAmount) withID() NameAndAmount {
+	cram.ID = ensureID(cram.ID)
+	return cram
+}
+
+// contextName is what we prefix the line item's validation errors with.
+func (cram NameAndAmount) contextName(idx int) string {
+	return idOrIndex(cram.ID, idx)
+}
+
+// ensureID assigns a new ID to anything in a list that doesn't have one yet.
+func ensureID(id SafeUUID) SafeUUID {
+	if len(id) == 0 {
+		return NewSafeUUID()
+	}
+	return id
+}
+
+// idOrIndex names an item within a list for validation errors. We prefer the ID since it doesn't shift when the list is edited, but we fall back to the index if the ID itself is bad.
+func idOrIndex(id SafeUUID, idx int) string {
+	if _, err := id.GetValidated(); err != nil {
+		return strconv.Itoa(idx)
+	}
+	return string(id)
+}
+
+// validateUniqueIDs makes sure no two line items within the same list share an ID.
+func validateUniqueIDs(items []NameAndAmount) error {
+	errs := make([]error, 0)
+	seen := map[SafeUUID]bool{}
+	for idx, item := range items {
+		if seen[item.ID] {
+			dupErr := common.NewValidationError("id", common.DuplicateCode, "lineItemId")
+			errs = append(errs, common.AddValidationContext(dupErr, item.contextName(idx)))
+		}
+		seen[item.ID] = true
+	}
+	return common.CombineErrors(errs...)
+}
+
 type NamesAndAmounts []NameAndAmount
 
 func (items NamesAndAmounts) GetValidated() (NamesAndAmounts, error) {
 	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(items))
 	var itemErr error
 	for i, item := range items {
+		item = item.withID()
 		items[i], itemErr = item.GetValidated()
-		errs = append(errs, common.AddValidationContext(itemErr, strconv.Itoa(i)))
+		errs = append(errs, common.AddValidationContext(itemErr, item.contextName(i)))
+		lineItems = append(lineItems, item)
 	}
+	errs = append(errs, validateUniqueIDs(lineItems))
 	err := common.CombineErrors(errs...)
 	if err != nil {
 		return NamesAndAmounts{}, err
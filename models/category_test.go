@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamesAndAmountsGetValidatedAssignsIDs(t *testing.T) {
+	existingID := NewSafeUUID()
+	items := NamesAndAmounts{
+		{ID: existingID, Name: "Rent", Amount: Amount{AmountCents: 100000}},
+		{Name: "Power", Amount: Amount{AmountCents: 10000}},
+	}
+	validated, err := items.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, existingID, validated[0].ID, "existing IDs should be kept")
+	_, idErr := validated[1].ID.GetValidated()
+	assert.Nil(t, idErr, "new line items should be assigned an ID")
+}
+
+func TestNamesAndAmountsGetValidatedErrorsUseIDs(t *testing.T) {
+	id := NewSafeUUID()
+	items := NamesAndAmounts{
+		{ID: id, Name: "", Amount: Amount{AmountCents: 100}},
+		{ID: "nope", Name: "Power", Amount: Amount{AmountCents: 100}},
+	}
+	_, err := items.GetValidated()
+	validationErr, ok := common.GetValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a validation error, got %#v", err)
+	}
+	fieldNames := []string{}
+	for _, field := range validationErr.Fields {
+		fieldNames = append(fieldNames, field.FieldName)
+	}
+	assert.Equal(t, []string{string(id) + ".name", "1.id"}, fieldNames)
+}
+
+func TestNamesAndAmountsGetValidatedDuplicateIDs(t *testing.T) {
+	id := NewSafeUUID()
+	items := NamesAndAmounts{
+		{ID: id, Name: "Rent", Amount: Amount{AmountCents: 100}},
+		{ID: id, Name: "Power", Amount: Amount{AmountCents: 100}},
+	}
+	_, err := items.GetValidated()
+	assert.Equal(t, common.NewValidationError(string(id)+".id", common.DuplicateCode, "lineItemId"), err)
+}
+
+func TestNameAndAmountGetValidatedCategoryID(t *testing.T) {
+	categoryID := NewSafeUUID()
+	item := NameAndAmount{ID: NewSafeUUID(), Name: "Rent", CategoryID: SafeUUID(strings.ToUpper(string(categoryID))), Amount: Amount{AmountCents: 100}}
+	validated, err := item.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, categoryID, validated.CategoryID, "category IDs should be normalized")
+
+	item.CategoryID = "nope"
+	_, err = item.GetValidated()
+	assert.Equal(t, common.NewValidationError("categoryId", common.BadUUIDFormatCode, ""), err)
+}
+
+func TestPlanLineItems(t *testing.T) {
+	plan := Plan{
+		Incomes: ManyPlannedIncomes{{NameAndAmount: NameAndAmount{ID: "a"}}},
+		Bills:   ManyPlannedBills{{NameAndAmount: NameAndAmount{ID: "b"}}},
+	}
+	refs := plan.LineItems()
+	if assert.Len(t, refs, 2) {
+		assert.Equal(t, "incomes.a", refs[0].FieldName)
+		assert.Equal(t, "bills.b", refs[1].FieldName)
+		// Editing through the reference should edit the plan itself.
+		refs[1].Item.CategoryID = "c"
+		assert.Equal(t, SafeUUID("c"), plan.Bills[0].CategoryID)
+	}
+}
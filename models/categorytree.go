@@ -0,0 +1,129 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// CategoryNode is a category along with its subcategories, for listing categories as a tree.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+// BuildCategoryTree arranges categories under their parents. Siblings are ordered by sort order, then name. A category whose parent doesn't exist is treated as a top-level one.
+func BuildCategoryTree(categories []Category) []CategoryNode {
+	exists := map[SafeUUID]bool{}
+	for _, category := range categories {
+		exists[category.SafeID()] = true
+	}
+	byParent := map[SafeUUID][]Category{}
+	for _, category := range categories {
+		parentID := category.ParentID
+		if !exists[parentID] {
+			parentID = ""
+		}
+		byParent[parentID] = append(byParent[parentID], category)
+	}
+	return categoryNodes(byParent, "")
+}
+
+// Builds the nodes for the parent's children, and their children, and so on.
+func categoryNodes(byParent map[SafeUUID][]Category, parentID SafeUUID) []CategoryNode {
+	children := byParent[parentID]
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].SortOrder != children[j].SortOrder {
+			return children[i].SortOrder < children[j].SortOrder
+		}
+		return children[i].Name < children[j].Name
+	})
+	nodes := make([]CategoryNode, 0, len(children))
+	for _, child := range children {
+		nodes = append(nodes, CategoryNode{
+			Category: child,
+			Children: categoryNodes(byParent, child.SafeID()),
+		})
+	}
+	return nodes
+}
+
+// ValidateCategoryParent makes sure the category with this ID can be placed under parentID: the parent must be one of the categories, and it can't be the category itself or one of its subcategories. Leave id empty for a category that doesn't exist yet.
+func ValidateCategoryParent(categories []Category, id, parentID SafeUUID) error {
+	if len(parentID) == 0 {
+		return nil
+	}
+	parents := map[SafeUUID]SafeUUID{}
+	for _, category := range categories {
+		parents[category.SafeID()] = category.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return common.NewValidationError("parentId", common.NonexistentRefCode, "category")
+	}
+	// Walk up from the new parent; if we pass through this category, it would become its own ancestor. We can't take more steps than there are categories, unless there's already a cycle.
+	ancestor := parentID
+	for steps := 0; len(ancestor) > 0 && steps <= len(categories); steps++ {
+		if ancestor == id {
+			return common.NewValidationError("parentId", common.CycleCode, "")
+		}
+		ancestor = parents[ancestor]
+	}
+	return nil
+}
+
+// CategoryTotal is how much is set aside for one category. Amount counts the line items in this category alone, while TotalAmount rolls up all of its subcategories too.
+type CategoryTotal struct {
+	CategoryID SafeUUID `json:"categoryId,omitempty"`
+	Name       string   `json:"name"`
+	Amount
+	TotalCents int             `json:"totalAmount"`
+	Children   []CategoryTotal `json:"children"`
+}
+
+// UncategorizedName labels the total for line items that don't refer to any category.
+const UncategorizedName = "Uncategorized"
+
+// SummarizeByCategory totals the line items' amounts for each category in the tree, rolling each group's subcategories up into it. Items without a category (or whose category no longer exists) are totaled last, as uncategorized.
+func SummarizeByCategory(tree []CategoryNode, items []LineItemRef) []CategoryTotal {
+	amounts := map[SafeUUID]int{}
+	for _, ref := range items {
+		amounts[ref.Item.CategoryID] += ref.Item.AmountCents
+	}
+	totals := categoryTotals(tree, amounts)
+
+	// Whatever's left didn't match a category in the tree.
+	uncategorized := 0
+	for _, amount := range amounts {
+		uncategorized += amount
+	}
+	if uncategorized > 0 {
+		totals = append(totals, CategoryTotal{
+			Name:       UncategorizedName,
+			Amount:     Amount{AmountCents: uncategorized},
+			TotalCents: uncategorized,
+			Children:   []CategoryTotal{},
+		})
+	}
+	return totals
+}
+
+// Totals each node, removing the amounts it uses so the caller can tell what's left over.
+func categoryTotals(nodes []CategoryNode, amounts map[SafeUUID]int) []CategoryTotal {
+	totals := make([]CategoryTotal, 0, len(nodes))
+	for _, node := range nodes {
+		id := node.SafeID()
+		total := CategoryTotal{
+			CategoryID: id,
+			Name:       node.Name,
+			Amount:     Amount{AmountCents: amounts[id]},
+			Children:   categoryTotals(node.Children, amounts),
+		}
+		delete(amounts, id)
+		total.TotalCents = total.AmountCents
+		for _, child := range total.Children {
+			total.TotalCents += child.TotalCents
+		}
+		totals = append(totals, total)
+	}
+	return totals
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Makes a set of categories: Housing > (Utilities, Rent), and Food.
+func testCategories() (housing, rent, utilities, food Category) {
+	housing = Category{ID: uuid.NewV4(), Name: "Housing", SortOrder: 1}
+	rent = Category{ID: uuid.NewV4(), Name: "Rent", ParentID: housing.SafeID(), SortOrder: 2}
+	utilities = Category{ID: uuid.NewV4(), Name: "Utilities", ParentID: housing.SafeID(), SortOrder: 1}
+	food = Category{ID: uuid.NewV4(), Name: "Food", SortOrder: 2}
+	return
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	housing, rent, utilities, food := testCategories()
+	orphan := Category{ID: uuid.NewV4(), Name: "Orphan", ParentID: NewSafeUUID(), SortOrder: 2}
+	tree := BuildCategoryTree([]Category{rent, food, orphan, utilities, housing})
+
+	names := []string{}
+	for _, node := range tree {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"Housing", "Food", "Orphan"}, names, "siblings should be ordered by sort order, then name, with orphans at the top level")
+	if assert.Len(t, tree[0].Children, 2) {
+		assert.Equal(t, "Utilities", tree[0].Children[0].Name)
+		assert.Equal(t, "Rent", tree[0].Children[1].Name)
+		assert.Equal(t, []CategoryNode{}, tree[0].Children[1].Children)
+	}
+}
+
+func TestValidateCategoryParent(t *testing.T) {
+	housing, rent, utilities, food := testCategories()
+	all := []Category{housing, rent, utilities, food}
+	for _, testCase := range []struct {
+		desc         string
+		id           SafeUUID
+		parentID     SafeUUID
+		expectedCode string
+	}{
+		{"no parent", housing.SafeID(), "", ""},
+		{"new category", "", housing.SafeID(), ""},
+		{"move to another group", rent.SafeID(), food.SafeID(), ""},
+		{"missing parent", rent.SafeID(), NewSafeUUID(), common.NonexistentRefCode},
+		{"own parent", housing.SafeID(), housing.SafeID(), common.CycleCode},
+		{"under its own subcategory", housing.SafeID(), rent.SafeID(), common.CycleCode},
+	} {
+		err := ValidateCategoryParent(all, testCase.id, testCase.parentID)
+		if testCase.expectedCode == "" {
+			assert.Nil(t, err, "CASE: %s", testCase.desc)
+			continue
+		}
+		validationErr, ok := common.GetValidationError(err)
+		if assert.True(t, ok, "CASE: %s, expected a validation error, got %#v", testCase.desc, err) {
+			assert.Equal(t, "parentId", validationErr.Fields[0].FieldName, "CASE: %s", testCase.desc)
+			assert.Equal(t, testCase.expectedCode, validationErr.Fields[0].Code, "CASE: %s", testCase.desc)
+		}
+	}
+}
+
+func TestSummarizeByCategory(t *testing.T) {
+	housing, rent, utilities, food := testCategories()
+	tree := BuildCategoryTree([]Category{housing, rent, utilities, food})
+	items := []NameAndAmount{
+		{Name: "Rent", CategoryID: rent.SafeID(), Amount: Amount{AmountCents: 100000}},
+		{Name: "Power", CategoryID: utilities.SafeID(), Amount: Amount{AmountCents: 8000}},
+		{Name: "Water", CategoryID: utilities.SafeID(), Amount: Amount{AmountCents: 2000}},
+		{Name: "Insurance", CategoryID: housing.SafeID(), Amount: Amount{AmountCents: 500}},
+		{Name: "Gifts", Amount: Amount{AmountCents: 300}},
+		{Name: "Gone", CategoryID: NewSafeUUID(), Amount: Amount{AmountCents: 200}},
+	}
+	refs := []LineItemRef{}
+	for idx := range items {
+		refs = append(refs, lineItemRef("expenses", &items[idx]))
+	}
+
+	totals := SummarizeByCategory(tree, refs)
+	if !assert.Len(t, totals, 3) {
+		return
+	}
+	assert.Equal(t, "Housing", totals[0].Name)
+	assert.Equal(t, 500, totals[0].AmountCents)
+	assert.Equal(t, 110500, totals[0].TotalCents)
+	assert.Equal(t, 10000, totals[0].Children[0].TotalCents)
+	assert.Equal(t, 100000, totals[0].Children[1].TotalCents)
+	assert.Equal(t, 0, totals[1].TotalCents)
+	assert.Equal(t, CategoryTotal{Name: UncategorizedName, Amount: Amount{AmountCents: 500}, TotalCents: 500, Children: []CategoryTotal{}}, totals[2])
+}
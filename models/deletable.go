@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Deletable lets a document be moved to the trash instead of being removed outright, so it can be restored until it's purged. Models embed it with `bson:",inline"` so the trash selectors in common find "deleted" on the document itself.
+type Deletable struct {
+	Deleted *time.Time `json:"deleted,omitempty" bson:"deleted,omitempty" readonly:"true"`
+}
+
+// MarkDeleted records that the document was moved to the trash just now.
+func (d *Deletable) MarkDeleted() {
+	now := time.Now()
+	d.Deleted = &now
+}
+
+// Restore takes the document back out of the trash.
+func (d *Deletable) Restore() {
+	d.Deleted = nil
+}
+
+// TrashResources lists each kind of resource that can be in the trash.
+var TrashResources = []string{"categories", "plans", "budgets"}
+
+// TrashItem is a deleted resource waiting in the trash, along with the document as it was when deleted.
+type TrashItem struct {
+	Resource string      `json:"resource"`
+	ID       string      `json:"id"`
+	Deleted  time.Time   `json:"deleted"`
+	Document interface{} `json:"document"`
+}
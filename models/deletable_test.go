@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// common.NotDeleted matches on a top-level "deleted" field, so each deletable model must store it there, and leave it out entirely when it isn't deleted.
+func TestDeletedIsStoredAtTopLevel(t *testing.T) {
+	for _, deleted := range []bool{true, false} {
+		plan := Plan{}
+		if deleted {
+			plan.MarkDeleted()
+		}
+		data, err := bson.Marshal(plan)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		stored := bson.M{}
+		assert.Nil(t, bson.Unmarshal(data, &stored))
+		_, ok := stored["deleted"]
+		assert.Equal(t, deleted, ok)
+	}
+}
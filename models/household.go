@@ -0,0 +1,218 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// These are the roles a household member can have. Owners manage the household, editors can change its plans and budgets, and viewers can only look.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Roles lists every role, from most to least trusted.
+var Roles = []string{RoleOwner, RoleEditor, RoleViewer}
+
+var roleRanks = map[string]int{
+	RoleOwner:  3,
+	RoleEditor: 2,
+	RoleViewer: 1,
+}
+
+// IsRole says whether this is one of the roles we know about.
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows says whether someone with this role may do something that needs at least the required role.
+func RoleAllows(role, required string) bool {
+	return IsRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// Household is a group of users who budget together. Its members share its plans, budgets, and categories, as far as their roles allow.
+type Household struct {
+	ID          SafeUUID     `json:"id" bson:"_id" readonly:"true"`
+	Name        string       `json:"name"`
+	Members     []Member     `json:"members" readonly:"true"`
+	Invitations []Invitation `json:"invitations" readonly:"true"`
+	Timestamped
+	Versioned `bson:",inline"`
+}
+
+// Member is a user who belongs to a household.
+type Member struct {
+	UserID SafeUUID  `json:"userId"`
+	Email  string    `json:"email"`
+	Role   string    `json:"role"`
+	Joined time.Time `json:"joined"`
+}
+
+// Invitation asks whoever has this email address to join a household with the given role.
+type Invitation struct {
+	ID        SafeUUID  `json:"id" readonly:"true"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy SafeUUID  `json:"invitedBy" readonly:"true"`
+	Invited   time.Time `json:"invited" readonly:"true"`
+}
+
+// PendingInvitation is an invitation someone has received, along with the household it's for.
+type PendingInvitation struct {
+	Invitation
+	HouseholdID   SafeUUID `json:"householdId"`
+	HouseholdName string   `json:"householdName"`
+}
+
+// RoleChange is what an owner sends to give a member a different role.
+type RoleChange struct {
+	Role string `json:"role"`
+}
+
+// GetValidated returns a sanitized copy if the household has a name; otherwise, it returns an error.
+func (household Household) GetValidated() (Household, error) {
+	household.Name = strings.TrimSpace(household.Name)
+	if common.StringIsEmpty(household.Name) {
+		return Household{}, common.NewValidationError("name", common.RequiredTextCode, "name")
+	}
+	return household, nil
+}
+
+// GetValidated returns a sanitized copy if the invitation is for an email address and a known role; otherwise, it returns an error.
+func (invitation Invitation) GetValidated() (Invitation, error) {
+	invitation.Email = NormalizeEmail(invitation.Email)
+	err := common.CombineErrors(checkEmail(invitation.Email), checkRole(invitation.Role))
+	if err != nil {
+		return Invitation{}, err
+	}
+	return invitation, nil
+}
+
+// GetValidated returns the change if it's to a known role; otherwise, it returns an error.
+func (change RoleChange) GetValidated() (RoleChange, error) {
+	err := checkRole(change.Role)
+	if err != nil {
+		return RoleChange{}, err
+	}
+	return change, nil
+}
+
+func checkRole(role string) error {
+	if !IsRole(role) {
+		return common.NewValidationError("role", common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(Roles, ", ")))
+	}
+	return nil
+}
+
+// RoleOf returns the user's role in the household, if they're a member.
+func (household Household) RoleOf(userID SafeUUID) (string, bool) {
+	for _, member := range household.Members {
+		if member.UserID == userID {
+			return member.Role, true
+		}
+	}
+	return "", false
+}
+
+// Invite adds an (already validated) invitation, unless that email address already belongs to a member or has been invited.
+func (household *Household) Invite(invitation Invitation) error {
+	for _, member := range household.Members {
+		if member.Email == invitation.Email {
+			return common.NewValidationError("email", common.DuplicateCode, "member")
+		}
+	}
+	for _, existing := range household.Invitations {
+		if existing.Email == invitation.Email {
+			return common.NewValidationError("email", common.DuplicateCode, "invited")
+		}
+	}
+	household.Invitations = append(household.Invitations, invitation)
+	return nil
+}
+
+// Revoke removes an invitation before it's accepted.
+func (household *Household) Revoke(invitationID SafeUUID) error {
+	idx := household.invitationIndex(invitationID)
+	if idx < 0 {
+		return common.NotFoundErr
+	}
+	household.Invitations = append(household.Invitations[:idx], household.Invitations[idx+1:]...)
+	return nil
+}
+
+// Accept makes the user a member with the role they were invited with. Only the user the invitation was sent to can accept it; for anyone else, it doesn't exist.
+func (household *Household) Accept(invitationID SafeUUID, user User) error {
+	idx := household.invitationIndex(invitationID)
+	if idx < 0 || household.Invitations[idx].Email != user.Email {
+		return common.NotFoundErr
+	}
+	household.Members = append(household.Members, Member{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   household.Invitations[idx].Role,
+		Joined: time.Now(),
+	})
+	household.Invitations = append(household.Invitations[:idx], household.Invitations[idx+1:]...)
+	return nil
+}
+
+// SetRole gives a member a different role, as long as the household still has an owner afterward.
+func (household *Household) SetRole(userID SafeUUID, role string) error {
+	idx := household.memberIndex(userID)
+	if idx < 0 {
+		return common.NotFoundErr
+	}
+	if household.isLastOwner(idx) && role != RoleOwner {
+		return common.LastOwnerErr
+	}
+	household.Members[idx].Role = role
+	return nil
+}
+
+// RemoveMember takes a member out of the household, as long as the household still has an owner afterward.
+func (household *Household) RemoveMember(userID SafeUUID) error {
+	idx := household.memberIndex(userID)
+	if idx < 0 {
+		return common.NotFoundErr
+	}
+	if household.isLastOwner(idx) {
+		return common.LastOwnerErr
+	}
+	household.Members = append(household.Members[:idx], household.Members[idx+1:]...)
+	return nil
+}
+
+func (household Household) memberIndex(userID SafeUUID) int {
+	for idx, member := range household.Members {
+		if member.UserID == userID {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (household Household) invitationIndex(invitationID SafeUUID) int {
+	for idx, invitation := range household.Invitations {
+		if invitation.ID == invitationID {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Says whether the member at this index is the household's only owner.
+func (household Household) isLastOwner(idx int) bool {
+	if household.Members[idx].Role != RoleOwner {
+		return false
+	}
+	for other, member := range household.Members {
+		if other != idx && member.Role == RoleOwner {
+			return false
+		}
+	}
+	return true
+}
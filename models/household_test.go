@@ -0,0 +1,100 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAllows(t *testing.T) {
+	for _, testCase := range []struct {
+		role, required string
+		expected       bool
+	}{
+		{RoleOwner, RoleEditor, true},
+		{RoleEditor, RoleEditor, true},
+		{RoleViewer, RoleEditor, false},
+		{RoleViewer, RoleViewer, true},
+		{RoleEditor, RoleOwner, false},
+		{"", RoleViewer, false},
+		{"admin", RoleViewer, false},
+	} {
+		assert.Equal(t, testCase.expected, RoleAllows(testCase.role, testCase.required), "CASE: %q needing %q", testCase.role, testCase.required)
+	}
+}
+
+func TestInvitationGetValidated(t *testing.T) {
+	validated, err := Invitation{Email: " Sam@Example.com", Role: RoleViewer}.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, "sam@example.com", validated.Email)
+
+	_, err = Invitation{Email: "sam", Role: "boss"}.GetValidated()
+	validationErr, ok := common.GetValidationError(err)
+	if assert.True(t, ok) {
+		codes := map[string]string{}
+		for _, field := range validationErr.Fields {
+			codes[field.FieldName] = field.Code
+		}
+		assert.Equal(t, map[string]string{"email": common.BadEmailCode, "role": common.BadEnumChoiceCode}, codes)
+	}
+}
+
+// A household owned by pat, with sam as a viewer and an invitation for alex.
+func sampleHousehold() Household {
+	return Household{
+		Name: "Home",
+		Members: []Member{
+			{UserID: "pat", Email: "pat@example.com", Role: RoleOwner},
+			{UserID: "sam", Email: "sam@example.com", Role: RoleViewer},
+		},
+		Invitations: []Invitation{{ID: "inv1", Email: "alex@example.com", Role: RoleEditor}},
+	}
+}
+
+func TestHouseholdInvite(t *testing.T) {
+	household := sampleHousehold()
+	assert.Nil(t, household.Invite(Invitation{ID: "inv2", Email: "jo@example.com", Role: RoleViewer}))
+	assert.Len(t, household.Invitations, 2)
+
+	for _, email := range []string{"sam@example.com", "alex@example.com"} {
+		err := household.Invite(Invitation{ID: "inv3", Email: email, Role: RoleViewer})
+		validationErr, ok := common.GetValidationError(err)
+		if assert.True(t, ok, "CASE: %s", email) {
+			assert.Equal(t, common.DuplicateCode, validationErr.Fields[0].Code, "CASE: %s", email)
+		}
+	}
+}
+
+func TestHouseholdAccept(t *testing.T) {
+	household := sampleHousehold()
+	assert.Equal(t, common.NotFoundErr, household.Accept("inv1", User{ID: "jo", Email: "jo@example.com"}), "only the invited email should be able to accept")
+	assert.Equal(t, common.NotFoundErr, household.Accept("nope", User{ID: "alex", Email: "alex@example.com"}))
+
+	assert.Nil(t, household.Accept("inv1", User{ID: "alex", Email: "alex@example.com"}))
+	role, ok := household.RoleOf("alex")
+	assert.True(t, ok)
+	assert.Equal(t, RoleEditor, role)
+	assert.Empty(t, household.Invitations)
+}
+
+func TestHouseholdRevoke(t *testing.T) {
+	household := sampleHousehold()
+	assert.Equal(t, common.NotFoundErr, household.Revoke("nope"))
+	assert.Nil(t, household.Revoke("inv1"))
+	assert.Empty(t, household.Invitations)
+}
+
+func TestHouseholdKeepsAnOwner(t *testing.T) {
+	household := sampleHousehold()
+	assert.Equal(t, common.LastOwnerErr, household.SetRole("pat", RoleEditor))
+	assert.Equal(t, common.LastOwnerErr, household.RemoveMember("pat"))
+	assert.Equal(t, common.NotFoundErr, household.SetRole("jo", RoleEditor))
+
+	// Once there's another owner, the first can step down.
+	assert.Nil(t, household.SetRole("sam", RoleOwner))
+	assert.Nil(t, household.SetRole("pat", RoleViewer))
+	assert.Nil(t, household.RemoveMember("pat"))
+	_, ok := household.RoleOf("pat")
+	assert.False(t, ok)
+}
@@ -0,0 +1,11 @@
+package models
+
+// Owned ties a document to the tenant it belongs to. It's never shown to or accepted from clients; services set it from the caller. It must be embedded with `bson:",inline"`, or common.ForTenant won't match anything.
+type Owned struct {
+	Tenant string `json:"-" bson:"tenant"`
+}
+
+// SetTenant assigns the document to a tenant. This is useful when a composing model is first created.
+func (o *Owned) SetTenant(tenantID string) {
+	o.Tenant = tenantID
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"strconv"
 	"strings"
 
 	"github.com/hjkelly/zbbapi/common"
@@ -13,16 +12,22 @@ import (
 
 // Plan holds incomes, bills, expenses, and goals that define your expectation/plan for a typical month.
 type Plan struct {
-	ID              SafeUUID            `json:"id" bson:"_id"`
+	ID              SafeUUID            `json:"id" bson:"_id" readonly:"true"`
 	Incomes         ManyPlannedIncomes  `json:"incomes"`
 	Bills           ManyPlannedBills    `json:"bills"`
 	Expenses        ManyPlannedExpenses `json:"expenses"`
 	Savings         ManyPlannedSavings  `json:"savings"`
 	SavingsStrategy string              `json:"savingsStrategy"`
+	Totals          *SectionTotals      `json:"totals,omitempty" bson:"-" readonly:"true"`
 	Timestamped
+	Attributed `bson:",inline"`
+	Versioned  `bson:",inline"`
+	Deletable  `bson:",inline"`
+	Owned      `bson:",inline"`
+	Warned     `bson:",inline"`
 }
 
-// GetValidated returns a sanitized copy if all incomes, bills, expenses, and goals are properly defined; otherwise, it returns an error.
+// GetValidated returns a sanitized copy with its totals worked out if all incomes, bills, expenses, and goals are properly defined; otherwise, it returns an error. The copy is warned about anything that's allowed but looks like a mistake.
 func (plan Plan) GetValidated() (Plan, error) {
 	cleanIncomes, incomesErr := plan.Incomes.GetValidated()
 	cleanBills, billsErr := plan.Bills.GetValidated()
@@ -30,7 +35,7 @@ func (plan Plan) GetValidated() (Plan, error) {
 	cleanSavings, savingsErr := plan.Savings.GetValidated()
 	var savingsStrategyErr error
 	if !IsSavingsStrategy(plan.SavingsStrategy) {
-		savingsStrategyErr = common.NewValidationError("savingsStrategy", common.BadEnumChoiceCode, "You must provide a valid strategy: %s", strings.Join(SavingsStrategies, ", "))
+		savingsStrategyErr = common.NewValidationError("savingsStrategy", common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(SavingsStrategies, ", ")))
 	}
 
 	err := common.CombineErrors(
@@ -48,9 +53,57 @@ func (plan Plan) GetValidated() (Plan, error) {
 	plan.Bills = cleanBills
 	plan.Expenses = cleanExpenses
 	plan.Savings = cleanSavings
+	plan.summarize()
 	return plan, nil
 }
 
+// Works out the totals for a typical month, and warns about paydays and bills on days some months don't have, and about planning to spend more than comes in. Amounts are added up over a year first, since incomes and bills come around on different schedules.
+func (plan *Plan) summarize() {
+	plan.Warnings = nil
+	incomes, bills, expenses, savings := 0, 0, 0, 0
+	for idx, income := range plan.Incomes {
+		incomes += income.AmountCents * income.Schedule.timesPerYear()
+		for _, warning := range common.AddWarningContext(income.Schedule.warnings(), "incomes."+income.contextName(idx)+".every") {
+			plan.Warn(warning)
+		}
+	}
+	for idx, bill := range plan.Bills {
+		bills += bill.AmountCents * bill.Schedule.timesPerYear()
+		for _, warning := range common.AddWarningContext(bill.Schedule.warnings(), "bills."+bill.contextName(idx)+".every") {
+			plan.Warn(warning)
+		}
+	}
+	for _, expense := range plan.Expenses {
+		expenses += expense.AmountCents * 12
+	}
+	for _, saving := range plan.Savings {
+		savings += saving.AmountCents * 12
+	}
+
+	plan.Totals = newSectionTotals(perMonth(incomes), perMonth(bills), perMonth(expenses), perMonth(savings))
+	if bills+expenses+savings > incomes {
+		plan.Warn(common.NewWarning("", common.ExceedsIncomeCode, ""))
+	}
+}
+
+// LineItems points at every line item in the plan, so they can be edited in place (e.g. to reassign or expand their categories).
+func (plan *Plan) LineItems() []LineItemRef {
+	refs := make([]LineItemRef, 0)
+	for idx := range plan.Incomes {
+		refs = append(refs, lineItemRef("incomes", &plan.Incomes[idx].NameAndAmount))
+	}
+	for idx := range plan.Bills {
+		refs = append(refs, lineItemRef("bills", &plan.Bills[idx].NameAndAmount))
+	}
+	for idx := range plan.Expenses {
+		refs = append(refs, lineItemRef("expenses", &plan.Expenses[idx].NameAndAmount))
+	}
+	for idx := range plan.Savings {
+		refs = append(refs, lineItemRef("savings", &plan.Savings[idx].NameAndAmount))
+	}
+	return refs
+}
+
 // INCOMES ----------
 
 // PlannedIncome stores a single category reference, its amount within this budget, and the payday schedule.
@@ -83,11 +136,15 @@ type ManyPlannedIncomes []PlannedIncome
 // GetValidated returns a sanitized copy if all incomes are properly defined; otherwise, it returns an error.
 func (incomes ManyPlannedIncomes) GetValidated() (ManyPlannedIncomes, error) {
 	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(incomes))
 	for idx, income := range incomes {
 		var incomeErr error
+		income.NameAndAmount = income.NameAndAmount.withID()
 		incomes[idx], incomeErr = income.GetValidated()
-		errs = append(errs, common.AddValidationContext(incomeErr, strconv.Itoa(idx)))
+		errs = append(errs, common.AddValidationContext(incomeErr, income.contextName(idx)))
+		lineItems = append(lineItems, income.NameAndAmount)
 	}
+	errs = append(errs, validateUniqueIDs(lineItems))
 
 	err := common.CombineErrors(errs...)
 	if err != nil {
@@ -131,11 +188,15 @@ type ManyPlannedBills []PlannedBill
 // GetValidated returns a sanitized copy if all bills are properly defined; otherwise, it returns an error.
 func (bills ManyPlannedBills) GetValidated() (ManyPlannedBills, error) {
 	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(bills))
 	for idx, bill := range bills {
 		var billErr error
+		bill.NameAndAmount = bill.NameAndAmount.withID()
 		bills[idx], billErr = bill.GetValidated()
-		errs = append(errs, common.AddValidationContext(billErr, strconv.Itoa(idx)))
+		errs = append(errs, common.AddValidationContext(billErr, bill.contextName(idx)))
+		lineItems = append(lineItems, bill.NameAndAmount)
 	}
+	errs = append(errs, validateUniqueIDs(lineItems))
 
 	err := common.CombineErrors(errs...)
 	if err != nil {
@@ -168,11 +229,15 @@ type ManyPlannedExpenses []PlannedExpense
 // GetValidated returns a sanitized copy if all expenses are properly defined; otherwise, it returns an error.
 func (expenses ManyPlannedExpenses) GetValidated() (ManyPlannedExpenses, error) {
 	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(expenses))
 	for idx, expense := range expenses {
 		var expenseErr error
+		expense.NameAndAmount = expense.NameAndAmount.withID()
 		expenses[idx], expenseErr = expense.GetValidated()
-		errs = append(errs, common.AddValidationContext(expenseErr, strconv.Itoa(idx)))
+		errs = append(errs, common.AddValidationContext(expenseErr, expense.contextName(idx)))
+		lineItems = append(lineItems, expense.NameAndAmount)
 	}
+	errs = append(errs, validateUniqueIDs(lineItems))
 
 	err := common.CombineErrors(errs...)
 	if err != nil {
@@ -205,11 +270,15 @@ type ManyPlannedSavings []PlannedSaving
 // GetValidated returns a sanitized copy if all savings are properly defined; otherwise, it returns an error.
 func (savings ManyPlannedSavings) GetValidated() (ManyPlannedSavings, error) {
 	errs := make([]error, 0)
+	lineItems := make([]NameAndAmount, 0, len(savings))
 	for idx, saving := range savings {
 		var savingErr error
+		saving.NameAndAmount = saving.NameAndAmount.withID()
 		savings[idx], savingErr = saving.GetValidated()
-		errs = append(errs, common.AddValidationContext(savingErr, strconv.Itoa(idx)))
+		errs = append(errs, common.AddValidationContext(savingErr, saving.contextName(idx)))
+		lineItems = append(lineItems, saving.NameAndAmount)
 	}
+	errs = append(errs, validateUniqueIDs(lineItems))
 
 	err := common.CombineErrors(errs...)
 	if err != nil {
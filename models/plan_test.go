@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanGetValidatedWarnings(t *testing.T) {
+	day := func(d int) *int { return &d }
+	payID, rentID := NewSafeUUID(), NewSafeUUID()
+	days := []int{15, 30}
+	weekly := "Friday"
+	for _, testCase := range []struct {
+		desc     string
+		plan     Plan
+		expected []common.InvalidField
+	}{
+		{
+			desc: "balanced",
+			plan: Plan{
+				SavingsStrategy: "shared",
+				Incomes:         ManyPlannedIncomes{{NameAndAmount: NameAndAmount{ID: payID, Name: "Pay", Amount: Amount{AmountCents: 1000}}, Schedule: Schedule{Week: &weekly}}},
+				Bills:           ManyPlannedBills{{NameAndAmount: NameAndAmount{ID: rentID, Name: "Rent", Amount: Amount{AmountCents: 3000}}, Schedule: Schedule{Month: day(1)}}},
+				Expenses:        ManyPlannedExpenses{{NameAndAmount: NameAndAmount{Name: "Food", Amount: Amount{AmountCents: 1000}}}},
+			},
+			expected: nil,
+		},
+		{
+			desc: "spending more than comes in",
+			plan: Plan{
+				SavingsStrategy: "shared",
+				Incomes:         ManyPlannedIncomes{{NameAndAmount: NameAndAmount{ID: payID, Name: "Pay", Amount: Amount{AmountCents: 1000}}, Schedule: Schedule{Month: day(1)}}},
+				Savings:         ManyPlannedSavings{{NameAndAmount: NameAndAmount{Name: "Rainy day", Amount: Amount{AmountCents: 1001}}}},
+			},
+			expected: []common.InvalidField{common.NewWarning("", common.ExceedsIncomeCode, "")},
+		},
+		{
+			desc: "days some months don't have",
+			plan: Plan{
+				SavingsStrategy: "shared",
+				Incomes:         ManyPlannedIncomes{{NameAndAmount: NameAndAmount{ID: payID, Name: "Pay", Amount: Amount{AmountCents: 5000}}, Schedule: Schedule{HalfMonth: &days}}},
+				Bills:           ManyPlannedBills{{NameAndAmount: NameAndAmount{ID: rentID, Name: "Rent", Amount: Amount{AmountCents: 3000}}, Schedule: Schedule{Month: day(31)}}},
+			},
+			expected: []common.InvalidField{
+				common.NewWarning("incomes."+string(payID)+".every.halfMonthOnDays.1", common.DayNotInEveryMonthCode, "", common.Arg("day", 30)),
+				common.NewWarning("bills."+string(rentID)+".every.monthOnDay", common.DayNotInEveryMonthCode, "", common.Arg("day", 31)),
+			},
+		},
+	} {
+		validated, err := testCase.plan.GetValidated()
+		assert.Nil(t, err, "CASE: %s", testCase.desc)
+		assert.Equal(t, testCase.expected, validated.Warnings, "CASE: %s", testCase.desc)
+	}
+}
+
+func TestPlanGetValidatedTotals(t *testing.T) {
+	weekly := "Friday"
+	plan := Plan{
+		SavingsStrategy: "shared",
+		Incomes:         ManyPlannedIncomes{{NameAndAmount: NameAndAmount{Name: "Pay", Amount: Amount{AmountCents: 1000}}, Schedule: Schedule{Week: &weekly}}},
+		Expenses:        ManyPlannedExpenses{{NameAndAmount: NameAndAmount{Name: "Food", Amount: Amount{AmountCents: 2500}}}},
+		Savings:         ManyPlannedSavings{{NameAndAmount: NameAndAmount{Name: "Rainy day", Amount: Amount{AmountCents: 500}}}},
+	}
+	validated, err := plan.GetValidated()
+	assert.Nil(t, err)
+	// A weekly 1000 comes to 52000 a year, or 4333 in a typical month.
+	assert.Equal(t, &SectionTotals{
+		Incomes:  Amount{AmountCents: 4333},
+		Bills:    Amount{AmountCents: 0},
+		Expenses: Amount{AmountCents: 2500},
+		Savings:  Amount{AmountCents: 500},
+		Balance:  Amount{AmountCents: 1333},
+	}, validated.Totals)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// These are the kinds of change a revision can record.
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+)
+
+// Revision is an immutable record of one change to a resource: the full document as it was afterward, and which of its fields changed. Each revision is numbered by the version of the document it produced.
+type Revision struct {
+	Key        string               `json:"-" bson:"_id"`
+	Resource   string               `json:"resource" bson:"resource"`
+	DocumentID string               `json:"documentId" bson:"documentId"`
+	Version    int                  `json:"version" bson:"version"`
+	Action     string               `json:"action" bson:"action"`
+	Changes    []common.FieldChange `json:"changes" bson:"changes"`
+	Snapshot   json.RawMessage      `json:"snapshot" bson:"snapshot"`
+	Created    time.Time            `json:"created" bson:"created"`
+	Owned      `bson:",inline"`
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -32,18 +31,18 @@ func (s Schedule) GetValidated() (Schedule, error) {
 	if s.Month != nil {
 		defsFound = append(defsFound, "monthOnDay")
 		if *s.Month < 1 || *s.Month > 31 {
-			return Schedule{}, common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive).")
+			return Schedule{}, common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31))
 		}
 	}
 
 	if s.HalfMonth != nil {
 		defsFound = append(defsFound, "halfMonthOnDays")
 		if len(*s.HalfMonth) < 2 {
-			return Schedule{}, common.NewValidationError("halfMonthOnDays", common.MissingCode, "You must provide at least two days of the month.")
+			return Schedule{}, common.NewValidationError("halfMonthOnDays", common.MissingCode, "halfMonthDays")
 		}
 		for idx, day := range *s.HalfMonth {
 			if day < 1 || day > 31 {
-				return Schedule{}, common.NewValidationError("halfMonthOnDays."+strconv.Itoa(idx), common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive).")
+				return Schedule{}, common.NewValidationError("halfMonthOnDays."+strconv.Itoa(idx), common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31))
 			}
 		}
 	}
@@ -59,7 +58,7 @@ func (s Schedule) GetValidated() (Schedule, error) {
 	if s.Week != nil {
 		defsFound = append(defsFound, "weekOn")
 		if !IsDayOfWeek(*s.Week) {
-			return Schedule{}, common.NewValidationError("weekOn", common.BadEnumChoiceCode, fmt.Sprintf("You must specify the day of the week: %s", strings.Join(daysOfWeek, ", ")))
+			return Schedule{}, common.NewValidationError("weekOn", common.BadEnumChoiceCode, "", common.Arg("choices", strings.Join(daysOfWeek, ", ")))
 		}
 	}
 
@@ -68,12 +67,48 @@ func (s Schedule) GetValidated() (Schedule, error) {
 		if len(defsFound) > 1 {
 			code = common.TooManyCode
 		}
-		return Schedule{}, common.NewValidationError("", code, "You must specify exactly one schedule: yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn")
+		return Schedule{}, common.NewValidationError("", code, "schedule", common.Arg("choices", "yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn"))
 	}
 
 	return s, nil
 }
 
+// timesPerYear is how many times the schedule comes around in a typical year, so amounts on different schedules can be compared.
+func (s Schedule) timesPerYear() int {
+	switch {
+	case s.Year != nil:
+		return 1
+	case s.Month != nil:
+		return 12
+	case s.HalfMonth != nil:
+		return 12 * len(*s.HalfMonth)
+	case s.TwoWeeks != nil:
+		return 26
+	case s.Week != nil:
+		return 52
+	}
+	return 0
+}
+
+// warnings points out any days of the month the schedule falls on that some months don't have.
+func (s Schedule) warnings() []common.InvalidField {
+	warnings := make([]common.InvalidField, 0)
+	if s.Month != nil && *s.Month > shortestMonth {
+		warnings = append(warnings, common.NewWarning("monthOnDay", common.DayNotInEveryMonthCode, "", common.Arg("day", *s.Month)))
+	}
+	if s.HalfMonth != nil {
+		for idx, day := range *s.HalfMonth {
+			if day > shortestMonth {
+				warnings = append(warnings, common.NewWarning("halfMonthOnDays."+strconv.Itoa(idx), common.DayNotInEveryMonthCode, "", common.Arg("day", day)))
+			}
+		}
+	}
+	return warnings
+}
+
+// shortestMonth is how many days February has in a common year.
+const shortestMonth = 28
+
 var daysOfWeek = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 
 func IsDayOfWeek(input string) bool {
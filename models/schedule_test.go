@@ -70,79 +70,79 @@ func TestScheduleGetValidated(t *testing.T) {
 			desc:   "missing yearly",
 			input:  Schedule{Year: &common.Date{}},
 			result: Schedule{},
-			err:    common.NewValidationError("yearStarting", common.MissingCode, "You must provide a date."),
+			err:    common.NewValidationError("yearStarting", common.MissingCode, "date"),
 		},
 		{
 			desc:   "invalid yearly",
 			input:  Schedule{Year: &common.Date{Year: 2018, Month: 13, Day: 4}},
 			result: Schedule{},
-			err:    common.NewValidationError("yearStarting", common.BadDateCode, "Date must be in the format YYYY-MM-DD, and it must be a valid date."),
+			err:    common.NewValidationError("yearStarting", common.BadDateCode, ""),
 		},
 		{
 			desc:   "invalid monthly (too low)",
 			input:  Schedule{Month: &tooLowDay},
 			result: Schedule{},
-			err:    common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive)."),
+			err:    common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31)),
 		},
 		{
 			desc:   "invalid monthly (too high)",
 			input:  Schedule{Month: &tooHighDay},
 			result: Schedule{},
-			err:    common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive)."),
+			err:    common.NewValidationError("monthOnDay", common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31)),
 		},
 		{
 			desc:   "invalid semimonthly (no days)",
 			input:  Schedule{HalfMonth: &noDays},
 			result: Schedule{},
-			err:    common.NewValidationError("halfMonthOnDays", common.MissingCode, "You must provide at least two days of the month."),
+			err:    common.NewValidationError("halfMonthOnDays", common.MissingCode, "halfMonthDays"),
 		},
 		{
 			desc:   "invalid semimonthly (not enough days)",
 			input:  Schedule{HalfMonth: &notEnoughDays},
 			result: Schedule{},
-			err:    common.NewValidationError("halfMonthOnDays", common.MissingCode, "You must provide at least two days of the month."),
+			err:    common.NewValidationError("halfMonthOnDays", common.MissingCode, "halfMonthDays"),
 		},
 		{
 			desc:   "invalid semimonthly (days too low)",
 			input:  Schedule{HalfMonth: &lowDays},
 			result: Schedule{},
-			err:    common.NewValidationError("halfMonthOnDays.0", common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive)."),
+			err:    common.NewValidationError("halfMonthOnDays.0", common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31)),
 		},
 		{
 			desc:   "invalid semimonthly (days too high)",
 			input:  Schedule{HalfMonth: &highDays},
 			result: Schedule{},
-			err:    common.NewValidationError("halfMonthOnDays.1", common.NumOutOfRangeCode, "Must be between 1 and 31 (inclusive)."),
+			err:    common.NewValidationError("halfMonthOnDays.1", common.NumOutOfRangeCode, "between", common.Arg("min", 1), common.Arg("max", 31)),
 		},
 		{
 			desc:   "missing biweekly",
 			input:  Schedule{TwoWeeks: &common.Date{}},
 			result: Schedule{},
-			err:    common.NewValidationError("twoWeeksStarting", common.MissingCode, "You must provide a date."),
+			err:    common.NewValidationError("twoWeeksStarting", common.MissingCode, "date"),
 		},
 		{
 			desc:   "invalid biweekly",
 			input:  Schedule{TwoWeeks: &common.Date{Year: 2018, Month: 13, Day: 4}},
 			result: Schedule{},
-			err:    common.NewValidationError("twoWeeksStarting", common.BadDateCode, "Date must be in the format YYYY-MM-DD, and it must be a valid date."),
+			err:    common.NewValidationError("twoWeeksStarting", common.BadDateCode, ""),
 		},
 		{
 			desc:   "invalid weekly",
 			input:  Schedule{Week: &badDayOfWeek},
 			result: Schedule{},
-			err:    common.NewValidationError("weekOn", common.BadEnumChoiceCode, "You must specify the day of the week: Sunday, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday"),
+			err:    common.NewValidationError("weekOn", common.BadEnumChoiceCode, "", common.Arg("choices", "Sunday, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday")),
 		},
 		{
 			desc:   "no schedule defined",
 			input:  Schedule{},
 			result: Schedule{},
-			err:    common.NewValidationError("", common.MissingCode, "You must specify exactly one schedule: yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn"),
+			err:    common.NewValidationError("", common.MissingCode, "schedule", common.Arg("choices", "yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn")),
 		},
 		{
 			desc:   "too many schedules defined",
 			input:  Schedule{Year: &common.Date{Year: 2018, Month: 5, Day: 19}, Month: &minDay},
 			result: Schedule{},
-			err:    common.NewValidationError("", common.TooManyCode, "You must specify exactly one schedule: yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn"),
+			err:    common.NewValidationError("", common.TooManyCode, "schedule", common.Arg("choices", "yearStarting, monthOnDay, halfMonthOnDays, twoWeeksStarting, weekOn")),
 		},
 		// overlapping specs ----------
 		// ""
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Each stored model must come back from Mongo just as it was saved (lists are stored empty rather than null), with its tenant, trash, and version fields at the top level, where common's selectors look for them.
+func TestModelsRoundTripThroughBSON(t *testing.T) {
+	deleted := time.Unix(1526083200, 0)
+	weekly := "Friday"
+	for _, doc := range []interface{}{
+		Category{
+			ID:        uuid.NewV4(),
+			Name:      "Groceries",
+			Versioned: Versioned{Version: 3},
+			Deletable: Deletable{Deleted: &deleted},
+			Owned:     Owned{Tenant: "t1"},
+		},
+		Plan{
+			ID:              NewSafeUUID(),
+			Incomes:         ManyPlannedIncomes{{NameAndAmount: NameAndAmount{ID: NewSafeUUID(), Name: "Pay", Amount: Amount{AmountCents: 1000}}, Schedule: Schedule{Week: &weekly}}},
+			Bills:           ManyPlannedBills{},
+			Expenses:        ManyPlannedExpenses{},
+			Savings:         ManyPlannedSavings{},
+			SavingsStrategy: "shared",
+			Versioned:       Versioned{Version: 3},
+			Deletable:       Deletable{Deleted: &deleted},
+			Owned:           Owned{Tenant: "t1"},
+		},
+		Budget{
+			ID:        NewSafeUUID(),
+			StartDate: common.Date{Year: 2018, Month: 5, Day: 12},
+			EndDate:   common.Date{Year: 2018, Month: 5, Day: 26},
+			Incomes:   NamesAndAmounts{},
+			Bills:     BudgetBills{{NameAndAmount: NameAndAmount{ID: NewSafeUUID(), Name: "Rent", Amount: Amount{AmountCents: 1000}}, IsPaidAutomatically: true}},
+			Expenses:  NamesAndAmounts{},
+			Savings:   NamesAndAmounts{},
+			Checklist: Checklist{},
+			Versioned: Versioned{Version: 3},
+			Deletable: Deletable{Deleted: &deleted},
+			Owned:     Owned{Tenant: "t1"},
+		},
+	} {
+		data, err := bson.Marshal(doc)
+		if !assert.Nil(t, err, "%T", doc) {
+			continue
+		}
+		stored := bson.M{}
+		assert.Nil(t, bson.Unmarshal(data, &stored))
+		assert.Equal(t, "t1", stored["tenant"], "%T doesn't store its tenant at the top level", doc)
+		assert.Equal(t, deleted, stored["deleted"], "%T doesn't store when it was deleted at the top level", doc)
+		assert.Equal(t, 3, stored["version"], "%T doesn't store its version at the top level", doc)
+
+		loaded := reflect.New(reflect.TypeOf(doc))
+		assert.Nil(t, bson.Unmarshal(data, loaded.Interface()))
+		assert.Equal(t, doc, loaded.Elem().Interface(), "%T doesn't load the way it was saved", doc)
+	}
+}
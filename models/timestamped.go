@@ -4,8 +4,8 @@ import "time"
 
 // Timestamped records creation and modification timestamps.
 type Timestamped struct {
-	Created  time.Time `json:"created"`
-	Modified time.Time `json:"modified"`
+	Created  time.Time `json:"created" readonly:"true"`
+	Modified time.Time `json:"modified" readonly:"true"`
 }
 
 // SetCreationTimestamp sets creation and modification timestamps to now. This is useful when a composing model is first created.
@@ -0,0 +1,25 @@
+package models
+
+// SectionTotals adds up each section of a plan or budget, and what's left of the incomes once everything else is paid for. They're worked out during validation, and never stored.
+type SectionTotals struct {
+	Incomes  Amount `json:"incomes"`
+	Bills    Amount `json:"bills"`
+	Expenses Amount `json:"expenses"`
+	Savings  Amount `json:"savings"`
+	Balance  Amount `json:"balance"`
+}
+
+func newSectionTotals(incomes, bills, expenses, savings int) *SectionTotals {
+	return &SectionTotals{
+		Incomes:  Amount{AmountCents: incomes},
+		Bills:    Amount{AmountCents: bills},
+		Expenses: Amount{AmountCents: expenses},
+		Savings:  Amount{AmountCents: savings},
+		Balance:  Amount{AmountCents: incomes - bills - expenses - savings},
+	}
+}
+
+// Spreads a year's worth of cents over a typical month, rounding to the nearest cent.
+func perMonth(yearly int) int {
+	return (yearly + 6) / 12
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// MinPasswordLength is the shortest password we accept when registering.
+const MinPasswordLength = 8
+
+// User is someone who can log in. Their password is never stored, only a hash of it.
+type User struct {
+	ID           SafeUUID `json:"id" bson:"_id" readonly:"true"`
+	Email        string   `json:"email" bson:"email"`
+	PasswordHash []byte   `json:"-" bson:"passwordHash"`
+	Timestamped
+	Versioned `bson:",inline"`
+}
+
+// Credentials are what someone provides to register or log in.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// GetValidated returns a sanitized copy if the email looks like one and the password is long enough; otherwise, it returns an error.
+func (creds Credentials) GetValidated() (Credentials, error) {
+	errs := make([]error, 0)
+	cleanEmail := NormalizeEmail(creds.Email)
+	errs = append(errs, checkEmail(cleanEmail))
+	if len(creds.Password) == 0 {
+		errs = append(errs, common.NewValidationError("password", common.MissingCode, "password"))
+	} else if len(creds.Password) < MinPasswordLength {
+		errs = append(errs, common.NewValidationError("password", common.TooShortCode, "password", common.Arg("min", MinPasswordLength)))
+	}
+
+	err := common.CombineErrors(errs...)
+	if err != nil {
+		return Credentials{}, err
+	}
+	creds.Email = cleanEmail
+	return creds, nil
+}
+
+// Makes sure a normalized email address is present and looks like one, reporting any problem on the "email" field.
+func checkEmail(email string) error {
+	if len(email) == 0 {
+		return common.NewValidationError("email", common.MissingCode, "email")
+	} else if at := strings.Index(email, "@"); at < 1 || at == len(email)-1 || strings.ContainsAny(email, " \t\n") {
+		return common.NewValidationError("email", common.BadEmailCode, "")
+	}
+	return nil
+}
+
+// NormalizeEmail makes sure the same address is always written the same way, so it can be looked up.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Session is what someone gets for logging in: a token to send with each request, until it expires.
+type Session struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+	User    User      `json:"user"`
+}
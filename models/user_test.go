@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialsGetValidated(t *testing.T) {
+	validated, err := Credentials{Email: "  Pat@Example.com ", Password: "longenough"}.GetValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{Email: "pat@example.com", Password: "longenough"}, validated)
+
+	for _, testCase := range []struct {
+		creds         Credentials
+		expectedCodes map[string]string
+	}{
+		{Credentials{}, map[string]string{"email": common.MissingCode, "password": common.MissingCode}},
+		{Credentials{Email: "pat", Password: "short"}, map[string]string{"email": common.BadEmailCode, "password": common.TooShortCode}},
+		{Credentials{Email: "pat@", Password: "longenough"}, map[string]string{"email": common.BadEmailCode}},
+		{Credentials{Email: "p at@example.com", Password: "longenough"}, map[string]string{"email": common.BadEmailCode}},
+	} {
+		_, err := testCase.creds.GetValidated()
+		validationErr, ok := common.GetValidationError(err)
+		if !assert.True(t, ok, "CASE: %+v, expected a validation error", testCase.creds) {
+			continue
+		}
+		codes := map[string]string{}
+		for _, field := range validationErr.Fields {
+			codes[field.FieldName] = field.Code
+		}
+		assert.Equal(t, testCase.expectedCodes, codes, "CASE: %+v", testCase.creds)
+	}
+}
@@ -17,7 +17,7 @@ func NewSafeUUID() SafeUUID {
 func (raw SafeUUID) GetValidated() (SafeUUID, error) {
 	properUUID, err := uuid.FromString(string(raw))
 	if err != nil {
-		return "", common.NewValidationError("", common.BadUUIDFormatCode, "Double-check the ID you're trying to reference, because this one doesn't look right. It should be in the format of a UUID.")
+		return "", common.NewValidationError("", common.BadUUIDFormatCode, "")
 	}
 	return SafeUUID(properUUID.String()), nil
 }
@@ -0,0 +1,23 @@
+package models
+
+import "strconv"
+
+// Versioned counts how many times a document has been saved, so concurrent updates can be detected. Embed it with `bson:",inline"`, since common.VersionSelector looks for "version" at the top level.
+type Versioned struct {
+	Version int `json:"version" readonly:"true"`
+}
+
+// SetInitialVersion marks the first version. This is useful when a composing model is first created.
+func (v *Versioned) SetInitialVersion() {
+	v.Version = 1
+}
+
+// IncrementVersion moves to the next version. This is useful when a composing model is updated.
+func (v *Versioned) IncrementVersion() {
+	v.Version++
+}
+
+// ETag returns the version as a quoted HTTP entity tag.
+func (v Versioned) ETag() string {
+	return strconv.Quote(strconv.Itoa(v.Version))
+}
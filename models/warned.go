@@ -0,0 +1,18 @@
+package models
+
+import "github.com/hjkelly/zbbapi/common"
+
+// Warned carries warnings about a document that don't stop it from being saved, like a budget that spends more than it earns. They're worked out during validation and only reported in the response, never stored.
+type Warned struct {
+	Warnings []common.InvalidField `json:"warnings,omitempty" bson:"-" readonly:"true"`
+}
+
+// Warn adds a warning. This is useful while a composing model is being validated.
+func (w *Warned) Warn(warning common.InvalidField) {
+	w.Warnings = append(w.Warnings, warning)
+}
+
+// Localize puts the warnings' messages in the locale.
+func (w *Warned) Localize(locale string) {
+	w.Warnings = common.LocalizeFields(w.Warnings, locale)
+}
@@ -1,20 +1,126 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
 	"github.com/hjkelly/zbbapi/handlers/v1"
+	"github.com/hjkelly/zbbapi/metrics"
+	"github.com/hjkelly/zbbapi/services/ratelimit"
+	"github.com/hjkelly/zbbapi/services/trash"
 	"github.com/julienschmidt/httprouter"
 	"github.com/urfave/negroni"
 )
 
 func main() {
+	// Anything logged through the standard library, like configuration warnings, comes out as JSON too.
+	log.SetFlags(0)
+	log.SetOutput(common.LogWriter(common.LevelWarn))
+
+	cfg, err := config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		fatal("Invalid configuration: %s", err.Error())
+	}
+	level, _ := common.ParseLevel(cfg.LogLevel)
+	common.SetLogLevel(level)
+
 	router := httprouter.New()
-	v1.RegisterHandlers(router)
+	registerRoutes(router)
+	router.NotFound = http.HandlerFunc(v1.NotFound)
+	router.MethodNotAllowed = http.HandlerFunc(v1.MethodNotAllowed)
 
-	n := negroni.Classic()
+	n := negroni.New()
+	n.UseFunc(v1.RequestLogging)
+	n.UseFunc(v1.Localize)
+	n.UseFunc(v1.Instrument)
+	n.UseFunc(v1.Recover)
+	n.UseFunc(v1.RateLimit(rateLimitStore(cfg)))
 	n.UseHandler(router)
 
-	log.Fatal(http.ListenAndServe(":8080", n))
+	server := &http.Server{
+		Addr:         cfg.ListenAddress,
+		Handler:      n,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	stopped := make(chan struct{})
+	go shutDownOnSignal(server, cfg, stopped)
+
+	go prepareDatabase(cfg)
+	common.Log.Info("Listening on %s.", cfg.ListenAddress)
+	if cfg.TLSCertFile != "" {
+		err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		fatal("Couldn't serve: %s", err.Error())
+	}
+	<-stopped
+}
+
+// registerRoutes links everything the server handles to the router: the API, and the metrics Prometheus scrapes, which aren't part of it.
+func registerRoutes(router v1.Router) {
+	v1.RegisterHandlers(router)
+	metricsHandler := metrics.Handler()
+	router.Handle("GET", "/metrics", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		metricsHandler.ServeHTTP(w, r)
+	})
+}
+
+// How long to wait before trying failed migrations again.
+const migrationRetryInterval = 10 * time.Second
+
+// Waits for the Mongo server, then sets it up and starts the background work that depends on it. Until then, /readyz says we aren't ready, while /healthz says we're alive so we aren't restarted.
+func prepareDatabase(cfg *config.Config) {
+	err := common.WaitForMongo(cfg.MongoStartupTimeout)
+	if err != nil {
+		fatal("Gave up waiting for the Mongo server: %s", err.Error())
+	}
+	trash.StartSweeper(cfg.TrashRetention, cfg.TrashSweepInterval)
+	// Migrations that fail, say because the server went away again, are retried until they've all been applied.
+	for common.ApplyMigrations() != nil {
+		time.Sleep(migrationRetryInterval)
+	}
+}
+
+// Once we're asked to stop, stops accepting connections and lets the requests in progress finish, up to the shutdown timeout, then closes stopped.
+func shutDownOnSignal(server *http.Server, cfg *config.Config, stopped chan<- struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	received := <-signals
+	common.Log.Info("Received %s; finishing requests in progress.", received)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err != nil {
+		common.Log.Error("Couldn't finish every request before shutting down: %s", err.Error())
+	}
+	close(stopped)
+}
+
+// Logs why we can't go on, then exits.
+func fatal(format string, args ...interface{}) {
+	common.Log.Error(format, args...)
+	os.Exit(1)
+}
+
+// Keeps rate limits in memory, unless they need to be shared between several servers.
+func rateLimitStore(cfg *config.Config) ratelimit.Store {
+	if cfg.RateLimitStore == "mongo" {
+		return ratelimit.NewMongoStore()
+	}
+	return ratelimit.NewMemoryStore()
 }
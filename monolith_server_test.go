@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// undocumentedRoutes are served alongside the API, but aren't part of it, so they're left out of its OpenAPI spec.
+var undocumentedRoutes = map[string]bool{
+	"GET /metrics": true,
+}
+
+// recordingRouter is a real router that also remembers every route registered with it.
+type recordingRouter struct {
+	*httprouter.Router
+	routes []string
+}
+
+func (rec *recordingRouter) Handle(method, path string, handle httprouter.Handle) {
+	rec.routes = append(rec.routes, method+" "+path)
+	rec.Router.Handle(method, path, handle)
+}
+
+var pathParameter = regexp.MustCompile(`:(\w+)`)
+
+// Compares the routes the server registers with the spec it serves, in both directions.
+func TestOpenAPIHasEveryRoute(t *testing.T) {
+	rec := &recordingRouter{Router: httprouter.New()}
+	registerRoutes(rec)
+	recorder := httptest.NewRecorder()
+	rec.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/openapi.json", nil))
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if !assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &doc)) {
+		return
+	}
+
+	assert.NotEmpty(t, rec.routes)
+	registered := 0
+	for _, r := range rec.routes {
+		if undocumentedRoutes[r] {
+			continue
+		}
+		registered++
+		parts := strings.SplitN(r, " ", 2)
+		operations, ok := doc.Paths[pathParameter.ReplaceAllString(parts[1], "{$1}")]
+		if !assert.True(t, ok, "path for %s is missing from the spec", r) {
+			continue
+		}
+		_, ok = operations[strings.ToLower(parts[0])]
+		assert.True(t, ok, "operation for %s is missing from the spec", r)
+	}
+	documented := 0
+	for _, operations := range doc.Paths {
+		documented += len(operations)
+	}
+	assert.Equal(t, registered, documented, "the spec documents routes that aren't registered")
+	for r := range undocumentedRoutes {
+		assert.Contains(t, rec.routes, r, "undocumented route %s isn't registered", r)
+	}
+}
@@ -0,0 +1,190 @@
+package batch
+
+import (
+	"bytes"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/budgets"
+	"github.com/hjkelly/zbbapi/services/categories"
+	"github.com/hjkelly/zbbapi/services/plans"
+)
+
+// undoFunc reverses an operation that already succeeded.
+type undoFunc func() error
+
+// resource adapts one service package to the operations a batch can perform. Each operation that succeeds also returns a way to undo it.
+type resource struct {
+	create func(caller common.Caller, body []byte) (interface{}, undoFunc, error)
+	update func(caller common.Caller, id string, body []byte, expectedVersion int) (interface{}, undoFunc, error)
+	delete func(caller common.Caller, id string, expectedVersion int) (undoFunc, error)
+}
+
+var resources = map[string]resource{
+	"categories": {create: createCategory, update: updateCategory, delete: deleteCategory},
+	"plans":      {create: createPlan, update: updatePlan, delete: deletePlan},
+	"budgets":    {create: createBudget, update: updateBudget, delete: deleteBudget},
+}
+
+// Decodes an operation's body the same way a handler would decode a request body.
+func decodeBody(body []byte, result interface{}) error {
+	return common.DecodeStrict(bytes.NewReader(body), result)
+}
+
+// When the client doesn't care which version they're changing, we still pin the version we saw, so we know the change leaves it one version later, and undoing it only applies if it's still there.
+func pinVersion(expectedVersion, current int) int {
+	if expectedVersion == common.AnyVersion {
+		return current
+	}
+	return expectedVersion
+}
+
+// CATEGORIES ----------
+
+func createCategory(caller common.Caller, body []byte) (interface{}, undoFunc, error) {
+	input := models.Category{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := categories.Create(caller, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return categories.Purge(caller, result.ID.String())
+	}, nil
+}
+
+func updateCategory(caller common.Caller, id string, body []byte, expectedVersion int) (interface{}, undoFunc, error) {
+	input := models.Category{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous, err := categories.Retrieve(caller, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := categories.UpdateID(caller, id, input, pinVersion(expectedVersion, previous.Version))
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return categories.Overwrite(caller, *previous, result.Version)
+	}, nil
+}
+
+func deleteCategory(caller common.Caller, id string, expectedVersion int) (undoFunc, error) {
+	previous, err := categories.Retrieve(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	err = categories.Delete(caller, id, pinVersion(expectedVersion, previous.Version), "")
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return categories.Overwrite(caller, *previous, previous.Version+1)
+	}, nil
+}
+
+// PLANS ----------
+
+func createPlan(caller common.Caller, body []byte) (interface{}, undoFunc, error) {
+	input := models.Plan{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := plans.Create(caller, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return plans.Purge(caller, string(result.ID))
+	}, nil
+}
+
+func updatePlan(caller common.Caller, id string, body []byte, expectedVersion int) (interface{}, undoFunc, error) {
+	input := models.Plan{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous, err := plans.Retrieve(caller, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := plans.UpdateID(caller, id, input, pinVersion(expectedVersion, previous.Version))
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return plans.Overwrite(caller, *previous, result.Version)
+	}, nil
+}
+
+func deletePlan(caller common.Caller, id string, expectedVersion int) (undoFunc, error) {
+	previous, err := plans.Retrieve(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	err = plans.Delete(caller, id, pinVersion(expectedVersion, previous.Version))
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return plans.Overwrite(caller, *previous, previous.Version+1)
+	}, nil
+}
+
+// BUDGETS ----------
+
+func createBudget(caller common.Caller, body []byte) (interface{}, undoFunc, error) {
+	input := models.Budget{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := budgets.Create(caller, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return budgets.Purge(caller, string(result.ID))
+	}, nil
+}
+
+func updateBudget(caller common.Caller, id string, body []byte, expectedVersion int) (interface{}, undoFunc, error) {
+	input := models.Budget{}
+	err := decodeBody(body, &input)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous, err := budgets.Retrieve(caller, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := budgets.UpdateID(caller, id, input, pinVersion(expectedVersion, previous.Version))
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, func() error {
+		return budgets.Overwrite(caller, *previous, result.Version)
+	}, nil
+}
+
+func deleteBudget(caller common.Caller, id string, expectedVersion int) (undoFunc, error) {
+	previous, err := budgets.Retrieve(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	err = budgets.Delete(caller, id, pinVersion(expectedVersion, previous.Version))
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return budgets.Overwrite(caller, *previous, previous.Version+1)
+	}, nil
+}
@@ -0,0 +1,74 @@
+package batch
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// Run performs each operation in order, on the caller's behalf, and reports how each one went. Normally a failed operation doesn't affect the others, but in an atomic batch the first failure stops the batch and undoes every operation that already succeeded.
+func Run(caller common.Caller, req models.BatchRequest) (*models.BatchResponse, error) {
+	req, err := req.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.BatchResponse{
+		Results: make([]models.BatchResult, len(req.Operations)),
+	}
+	undos := make([]undoFunc, 0, len(req.Operations))
+	for idx, op := range req.Operations {
+		result, undo, err := perform(caller, op)
+		if err != nil {
+			status, body := common.ErrorResponse(err, caller.Locale)
+			response.Results[idx] = models.BatchResult{Status: status, Body: body}
+			if req.Atomic {
+				abortedStatus, aborted := common.ErrorResponse(common.BatchAbortedErr, caller.Locale)
+				for skipped := idx + 1; skipped < len(req.Operations); skipped++ {
+					response.Results[skipped] = models.BatchResult{Status: abortedStatus, Body: aborted}
+				}
+				response.RolledBack = rollback(caller.Logger(), undos)
+				return response, nil
+			}
+			continue
+		}
+		if body, ok := result.Body.(common.Localizable); ok {
+			body.Localize(caller.Locale)
+		}
+		response.Results[idx] = result
+		undos = append(undos, undo)
+	}
+	return response, nil
+}
+
+// Performs a single (already validated) operation, as long as the caller's access token (if they used one) lets them change that kind of resource.
+func perform(caller common.Caller, op models.BatchOperation) (models.BatchResult, undoFunc, error) {
+	if !caller.HasScope(op.Resource + ":write") {
+		return models.BatchResult{}, nil, common.InsufficientScopeErr
+	}
+	res := resources[op.Resource]
+	expectedVersion, _ := common.ParseIfMatch(op.IfMatch)
+	switch op.Op {
+	case models.BatchCreate:
+		body, undo, err := res.create(caller, op.Body)
+		return models.BatchResult{Status: 201, Body: body}, undo, err
+	case models.BatchUpdate:
+		body, undo, err := res.update(caller, op.ID, op.Body, expectedVersion)
+		return models.BatchResult{Status: 200, Body: body}, undo, err
+	default:
+		undo, err := res.delete(caller, op.ID, expectedVersion)
+		return models.BatchResult{Status: 204}, undo, err
+	}
+}
+
+// Undoes operations in the reverse order they were performed, and reports whether every one of them was undone.
+func rollback(logger common.Logger, undos []undoFunc) bool {
+	ok := true
+	for idx := len(undos) - 1; idx >= 0; idx-- {
+		err := undos[idx]()
+		if err != nil {
+			logger.Error("Couldn't undo batch operation: %s", err.Error())
+			ok = false
+		}
+	}
+	return ok
+}
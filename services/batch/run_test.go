@@ -0,0 +1,132 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResource records which operations were performed and undone, and fails creates whose body is `"fail"`.
+type fakeResource struct {
+	performed []string
+	undone    []string
+}
+
+func (fake *fakeResource) resource() resource {
+	return resource{
+		create: func(caller common.Caller, body []byte) (interface{}, undoFunc, error) {
+			if string(body) == `"fail"` {
+				return nil, nil, common.NewValidationError("name", common.MissingCode, "name")
+			}
+			fake.performed = append(fake.performed, "create "+string(body))
+			return string(body), func() error {
+				fake.undone = append(fake.undone, "create "+string(body))
+				return nil
+			}, nil
+		},
+		update: func(caller common.Caller, id string, body []byte, expectedVersion int) (interface{}, undoFunc, error) {
+			fake.performed = append(fake.performed, "update "+id)
+			return id, func() error {
+				fake.undone = append(fake.undone, "update "+id)
+				return nil
+			}, nil
+		},
+		delete: func(caller common.Caller, id string, expectedVersion int) (undoFunc, error) {
+			fake.performed = append(fake.performed, "delete "+id)
+			return func() error {
+				fake.undone = append(fake.undone, "delete "+id)
+				return errors.New("nope")
+			}, nil
+		},
+	}
+}
+
+func TestRunNonAtomic(t *testing.T) {
+	original := resources
+	defer func() { resources = original }()
+	fake := &fakeResource{}
+	resources = map[string]resource{"plans": fake.resource()}
+	response, err := Run(common.Caller{}, models.BatchRequest{
+		Operations: []models.BatchOperation{
+			{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"a"`)},
+			{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"fail"`)},
+			{Op: models.BatchUpdate, Resource: "plans", ID: "b", Body: json.RawMessage(`{}`)},
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, response.RolledBack)
+	assert.Equal(t, []string{"create \"a\"", "update b"}, fake.performed)
+	assert.Empty(t, fake.undone)
+	assert.Equal(t, 201, response.Results[0].Status)
+	assert.Equal(t, 422, response.Results[1].Status)
+	assert.Equal(t, 200, response.Results[2].Status)
+}
+
+func TestRunAtomicRollsBack(t *testing.T) {
+	original := resources
+	defer func() { resources = original }()
+	fake := &fakeResource{}
+	resources = map[string]resource{"plans": fake.resource()}
+	response, err := Run(common.Caller{}, models.BatchRequest{
+		Atomic: true,
+		Operations: []models.BatchOperation{
+			{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"a"`)},
+			{Op: models.BatchUpdate, Resource: "plans", ID: "b", Body: json.RawMessage(`{}`)},
+			{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"fail"`)},
+			{Op: models.BatchUpdate, Resource: "plans", ID: "c", Body: json.RawMessage(`{}`)},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, response.RolledBack)
+	assert.Equal(t, []string{"create \"a\"", "update b"}, fake.performed)
+	assert.Equal(t, []string{"update b", "create \"a\""}, fake.undone)
+	assert.Equal(t, 422, response.Results[2].Status)
+	assert.Equal(t, 424, response.Results[3].Status)
+	assert.Equal(t, common.ErrorEnvelope{Code: common.BatchAbortedErr.Code, Message: common.BatchAbortedErr.Message}, response.Results[3].Body)
+}
+
+func TestRunAtomicReportsFailedRollback(t *testing.T) {
+	original := resources
+	defer func() { resources = original }()
+	resources = map[string]resource{"plans": (&fakeResource{}).resource()}
+	response, err := Run(common.Caller{}, models.BatchRequest{
+		Atomic: true,
+		Operations: []models.BatchOperation{
+			{Op: models.BatchDelete, Resource: "plans", ID: "a"},
+			{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"fail"`)},
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, response.RolledBack)
+}
+
+func TestRunChecksScopes(t *testing.T) {
+	original := resources
+	defer func() { resources = original }()
+	fake := &fakeResource{}
+	resources = map[string]resource{"plans": fake.resource()}
+	ops := []models.BatchOperation{
+		{Op: models.BatchCreate, Resource: "plans", Body: json.RawMessage(`"a"`)},
+	}
+	response, err := Run(common.Caller{Scopes: []string{"plans:read"}}, models.BatchRequest{Operations: ops})
+	assert.Nil(t, err)
+	assert.Equal(t, 403, response.Results[0].Status)
+	assert.Empty(t, fake.performed, "a token that can only read plans shouldn't be able to create one")
+
+	response, err = Run(common.Caller{Scopes: []string{"plans:write"}}, models.BatchRequest{Operations: ops})
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.Results[0].Status)
+}
+
+func TestRunInvalid(t *testing.T) {
+	original := resources
+	defer func() { resources = original }()
+	resources = map[string]resource{"plans": (&fakeResource{}).resource()}
+	_, err := Run(common.Caller{}, models.BatchRequest{})
+	_, ok := common.GetValidationError(err)
+	assert.True(t, ok)
+}
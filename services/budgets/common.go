@@ -1,12 +1,23 @@
 package budgets
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/categories"
 )
 
 // Make sure this Budget has input sufficient enough to be saved.
-func getValidated(input models.Budget) (models.Budget, error) {
-	return input.GetValidated()
+func getValidated(caller common.Caller, input models.Budget) (models.Budget, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return input, err
+	}
+	// Only now that we know the category IDs are well-formed can we look them up.
+	err = categories.CheckRefs(caller, input.LineItems())
+	if err != nil {
+		return models.Budget{}, err
+	}
+	return input, nil
 }
 
 // Returns the updated Budget, which is the current Budget updated with the input data for the update.
@@ -18,5 +29,8 @@ func getUpdated(current, input models.Budget) models.Budget {
 	current.Bills = input.Bills
 	current.Savings = input.Savings
 	current.Checklist = input.Checklist
+	current.Balance = input.Balance
+	current.Totals = input.Totals
+	current.Warnings = input.Warnings
 	return current
 }
@@ -1,13 +1,15 @@
 package budgets
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
 )
 
 // Create validates and preps a Budget, then saves it via the controller's datastore.
-func Create(input models.Budget) (*models.Budget, error) {
+func Create(caller common.Caller, input models.Budget) (*models.Budget, error) {
 	var err error
-	input, err = getValidated(input)
+	input, err = getValidated(caller, input)
 	if err != nil {
 		return nil, err
 	}
@@ -18,12 +20,17 @@ func Create(input models.Budget) (*models.Budget, error) {
 	// prepare the rest of the resource
 	input.ID = models.NewSafeUUID()
 	input.SetCreationTimestamp()
+	input.SetCreator(caller.UserID)
+	input.SetInitialVersion()
+	input.SetTenant(caller.TenantID)
 
 	// save
-	ds := newDatastore()
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 	err = ds.C().Insert(input)
 	if err != nil {
 		return nil, err
 	}
+	revisions.Record(caller, resourceName, string(input.ID), input.Version, models.RevisionCreate, nil, input)
 	return &input, nil
 }
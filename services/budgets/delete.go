@@ -2,21 +2,25 @@ package budgets
 
 import (
 	"github.com/hjkelly/zbbapi/common"
-	mgo "gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
+	"github.com/hjkelly/zbbapi/models"
 )
 
-// Delete uses the datastore to remove this ID, if it exists.
-func Delete(id string) error {
-	ds := newDatastore()
-	err := ds.C().Remove(bson.M{
-		"_id": id,
-	})
+// Delete moves the Budget with this ID to the trash, if it exists. If expectedVersion isn't common.AnyVersion, the Budget must still be at that version.
+func Delete(caller common.Caller, id string, expectedVersion int) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
 	if err != nil {
-		if err == mgo.ErrNotFound {
-			return common.NotFoundErr
-		}
 		return err
 	}
-	return nil
+	if expectedVersion != common.AnyVersion && expectedVersion != current.Version {
+		return common.NewStaleError(current)
+	}
+
+	// Save it as a new version, so it can be restored later.
+	result := *current
+	result.MarkDeleted()
+	result.SetModificationTimestamp()
+	result.IncrementVersion()
+	return ds.save(*current, &result, models.RevisionDelete)
 }
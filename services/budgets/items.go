@@ -0,0 +1,72 @@
+package budgets
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// RetrieveItem fetches a single line item (e.g. one of the bills) from a Budget by their IDs.
+func RetrieveItem(caller common.Caller, id, section, itemID string) (interface{}, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+	return common.FindTaggedLineItem(current, section, itemID)
+}
+
+// UpdateItem replaces a single line item within a Budget, then validates and saves the whole Budget like UpdateID would.
+func UpdateItem(caller common.Caller, id, section, itemID string, item []byte, expectedVersion int) (interface{}, error) {
+	return editItem(caller, id, section, itemID, expectedVersion, func(current *models.Budget, input *models.Budget) error {
+		return common.ReplaceLineItem(current, section, itemID, item, input)
+	})
+}
+
+// PatchItem applies a JSON merge patch to a single line item within a Budget, then validates and saves the whole Budget like UpdateID would.
+func PatchItem(caller common.Caller, id, section, itemID string, patch []byte, expectedVersion int) (interface{}, error) {
+	return editItem(caller, id, section, itemID, expectedVersion, func(current *models.Budget, input *models.Budget) error {
+		return common.PatchLineItem(current, section, itemID, patch, input)
+	})
+}
+
+// DeleteItem removes a single line item from a Budget, then validates and saves the Budget like UpdateID would.
+func DeleteItem(caller common.Caller, id, section, itemID string, expectedVersion int) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return err
+	}
+	input := models.Budget{}
+	err = common.RemoveLineItem(current, section, itemID, &input)
+	if err != nil {
+		return err
+	}
+	// Checklist items can't point at a bill that's gone.
+	if section == "bills" {
+		input.Checklist = input.Checklist.UnlinkBill(models.SafeUUID(itemID))
+	}
+	_, err = ds.update(*current, input, expectedVersion)
+	return err
+}
+
+// Loads the Budget, lets edit build the full input from it, saves it, and returns the edited line item.
+func editItem(caller common.Caller, id, section, itemID string, expectedVersion int, edit func(current *models.Budget, input *models.Budget) error) (interface{}, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+	input := models.Budget{}
+	err = edit(current, &input)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ds.update(*current, input, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return common.FindTaggedLineItem(result, section, itemID)
+}
@@ -1,15 +1,17 @@
 package budgets
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
 	"gopkg.in/mgo.v2/bson"
 )
 
-// List returns all Budgets from the database.
-func List() ([]models.Budget, error) {
-	ds := newDatastore()
+// List returns all Budgets from the database, except those in the trash.
+func List(caller common.Caller) ([]models.Budget, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 	results := make([]models.Budget, 0)
-	err := ds.C().Find(bson.M{}).All(&results)
+	err := ds.find(common.NotDeleted(bson.M{})).All(&results)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,23 @@
+package budgets
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/metrics"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	metrics.NewGaugeFunc("zbbapi_budgets", "How many budgets there are across every tenant, not counting those in the trash.", count)
+}
+
+// Counts every tenant's Budgets, except those in the trash.
+func count() (float64, error) {
+	session, err := common.CopyMongoSession()
+	if err != nil {
+		return 0, err
+	}
+	ds := datastore{session, common.Caller{}}
+	defer ds.session.Close()
+	n, err := ds.C().Find(common.NotDeleted(bson.M{})).Count()
+	return float64(n), err
+}
@@ -0,0 +1,51 @@
+package budgets
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// Preview validates a Budget and works out its totals and warnings the same way Create would, but doesn't save it.
+func Preview(caller common.Caller, input models.Budget) (*models.Budget, error) {
+	input, err := getValidated(caller, input)
+	if err != nil {
+		return nil, err
+	}
+	return &input, nil
+}
+
+// PreviewUpdateID shows what UpdateID would save, without saving it.
+func PreviewUpdateID(caller common.Caller, id string, input models.Budget, expectedVersion int) (*models.Budget, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ds.revised(*current, input, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PreviewPatchID shows what PatchID would save, without saving it.
+func PreviewPatchID(caller common.Caller, id string, patch []byte, expectedVersion int) (*models.Budget, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := patched(*current, patch)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ds.revised(*current, input, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
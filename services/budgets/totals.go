@@ -0,0 +1,30 @@
+package budgets
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/categories"
+)
+
+// Totals sums the Budget's bills, expenses, and savings by category, rolling each group's subcategories up into it.
+func Totals(caller common.Caller, id string) ([]models.CategoryTotal, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := categories.Tree(caller)
+	if err != nil {
+		return nil, err
+	}
+
+	// Incomes aren't spending, so they'd muddle the totals.
+	outflows := make([]models.LineItemRef, 0)
+	for _, ref := range current.LineItems() {
+		if ref.Section != "incomes" {
+			outflows = append(outflows, ref)
+		}
+	}
+	return models.SummarizeByCategory(tree, outflows), nil
+}
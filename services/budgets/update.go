@@ -3,39 +3,55 @@ package budgets
 import (
 	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
-	mgo "gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
 )
 
-// UpdateID finds the current Budget by ID, updates all its user-updatable fields, and saves it again.
-func UpdateID(id string, input models.Budget) (*models.Budget, error) {
-	ds := newDatastore()
+// UpdateID finds the current Budget by ID, updates all its user-updatable fields, and saves it again. If expectedVersion isn't common.AnyVersion, the Budget must still be at that version.
+func UpdateID(caller common.Caller, id string, input models.Budget, expectedVersion int) (*models.Budget, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 
 	// Make sure the one we're updating exists.
-	current := models.Budget{}
-	err := ds.C().Find(bson.M{
-		"_id": id,
-	}).One(&current)
+	current, err := ds.findID(id)
 	if err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, common.NotFoundErr
-		}
 		return nil, err
 	}
 
-	// Validate the input and use it to update the current data.
-	input, err = getValidated(input)
+	return ds.update(*current, input, expectedVersion)
+}
+
+// Validates the input, uses it to update the current Budget, and saves the result as long as nobody else saved a newer version first.
+func (ds datastore) update(current, input models.Budget, expectedVersion int) (*models.Budget, error) {
+	return ds.revise(current, input, expectedVersion, models.RevisionUpdate)
+}
+
+// Does the work of update, recording the change as the given kind of revision.
+func (ds datastore) revise(current, input models.Budget, expectedVersion int, action string) (*models.Budget, error) {
+	result, err := ds.revised(current, input, expectedVersion)
 	if err != nil {
 		return nil, err
 	}
-	result := getUpdated(current, input)
 	result.SetModificationTimestamp()
+	result.IncrementVersion()
 
-	// Update the database with our new result.
-	err = ds.C().UpdateId(result.ID, result)
+	// Update the database with our new result, but only if it's still at the version we started from.
+	err = ds.save(current, &result, action)
 	if err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
+
+// Makes sure the current Budget is at the version the client expects, then validates the input and uses it to update the current data, without saving anything.
+func (ds datastore) revised(current, input models.Budget, expectedVersion int) (models.Budget, error) {
+	if expectedVersion != common.AnyVersion && expectedVersion != current.Version {
+		return models.Budget{}, common.NewStaleError(current)
+	}
+
+	// Validate the input and use it to update the current data.
+	input, err := getValidated(ds.caller, input)
+	if err != nil {
+		return models.Budget{}, err
+	}
+	return getUpdated(current, input), nil
+}
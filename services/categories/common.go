@@ -10,19 +10,40 @@ import (
 // Do any trimming, cleanup before validation.
 func sanitize(input models.Category) models.Category {
 	input.Name = strings.TrimSpace(input.Name)
+	input.ParentID = models.SafeUUID(strings.TrimSpace(string(input.ParentID)))
 	return input
 }
 
 // Make sure this category has input sufficient enough to be saved.
 func validate(input models.Category) error {
+	errs := make([]error, 0)
 	if common.StringIsEmpty(input.Name) {
-		return common.NewValidationError("name", "REQUIRED_TEXT", "You must provide a name.")
+		errs = append(errs, common.NewValidationError("name", common.RequiredTextCode, "name"))
 	}
-	return nil
+	if len(input.ParentID) > 0 {
+		_, err := input.ParentID.GetValidated()
+		errs = append(errs, common.AddValidationContext(err, "parentId"))
+	}
+	return common.CombineErrors(errs...)
+}
+
+// Makes sure the category with this ID (empty if it's new) can be placed under its parent without creating a cycle, and returns the parent's normalized ID. The input must already be validated.
+func validateParent(caller common.Caller, id models.SafeUUID, parentID models.SafeUUID) (models.SafeUUID, error) {
+	if len(parentID) == 0 {
+		return "", nil
+	}
+	parentID, _ = parentID.GetValidated()
+	all, err := List(caller)
+	if err != nil {
+		return "", err
+	}
+	return parentID, models.ValidateCategoryParent(all, id, parentID)
 }
 
 // Returns the updated category, which is the current category updated with the input data for the update.
 func getUpdated(current, input models.Category) models.Category {
 	current.Name = input.Name
+	current.ParentID = input.ParentID
+	current.SortOrder = input.SortOrder
 	return current
 }
@@ -1,28 +1,38 @@
 package categories
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
 	uuid "github.com/satori/go.uuid"
 )
 
 // Create validates and preps a Category, then saves it via the controller's datastore.
-func Create(input models.Category) (*models.Category, error) {
+func Create(caller common.Caller, input models.Category) (*models.Category, error) {
 	input = sanitize(input)
 	// Did they give us enough to save?
 	err := validate(input)
 	if err != nil {
 		return nil, err
 	}
+	input.ParentID, err = validateParent(caller, "", input.ParentID)
+	if err != nil {
+		return nil, err
+	}
 
 	// prepare the rest of the resource
 	input.ID = uuid.NewV4()
 	input.SetCreationTimestamp()
+	input.SetInitialVersion()
+	input.SetTenant(caller.TenantID)
 
 	// save
-	ds := newDatastore()
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 	err = ds.C().Insert(input)
 	if err != nil {
 		return nil, err
 	}
+	revisions.Record(caller, resourceName, input.ID.String(), input.Version, models.RevisionCreate, nil, input)
 	return &input, nil
 }
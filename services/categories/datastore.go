@@ -2,17 +2,73 @@ package categories
 
 import (
 	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
+	uuid "github.com/satori/go.uuid"
 	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
+// resourceName is what we call Categories in URLs and revision history.
+const resourceName = "categories"
+
+// datastore works with the caller's tenant's Categories only.
 type datastore struct {
 	session *mgo.Session
+	caller  common.Caller
+}
+
+func newDatastore(caller common.Caller) *datastore {
+	return &datastore{common.GetMongoSession(), caller}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "category").C("categories"))
+}
+
+// scoped narrows a selector to the caller's tenant.
+func (ds datastore) scoped(selector bson.M) bson.M {
+	return common.ForTenant(ds.caller.TenantID, selector)
+}
+
+// find queries the caller's tenant's Categories.
+func (ds datastore) find(selector bson.M) *common.Query {
+	return ds.C().Find(ds.scoped(selector))
+}
+
+// findID fetches a single Category by ID, translating a missing (or trashed) document into our NotFoundErr.
+func (ds datastore) findID(id string) (*models.Category, error) {
+	result := new(models.Category)
+	err := ds.find(common.NotDeleted(bson.M{
+		"_id": uuid.FromStringOrNil(id),
+	})).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
 }
 
-func newDatastore() *datastore {
-	return &datastore{common.GetMongoSession()}
+// conflict explains why a versioned update didn't match anything: either the Category is gone, or someone else saved a newer version.
+func (ds datastore) conflict(id string) error {
+	latest, err := ds.findID(id)
+	if err != nil {
+		return err
+	}
+	return common.NewStaleError(latest)
 }
 
-func (ds datastore) C() *mgo.Collection {
-	return ds.session.DB("category").C("categories")
+// save replaces the current Category with the result, but only if it's still at the version we started from, and records the change as a revision.
+func (ds datastore) save(current, result models.Category, action string) error {
+	err := ds.C().Update(ds.scoped(common.VersionSelector(current.ID, current.Version)), result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return ds.conflict(current.ID.String())
+		}
+		return err
+	}
+	revisions.Record(ds.caller, resourceName, result.ID.String(), result.Version, action, current, result)
+	return nil
 }
@@ -1,23 +1,91 @@
 package categories
 
 import (
+	"strings"
+
 	"github.com/hjkelly/zbbapi/common"
-	uuid "github.com/satori/go.uuid"
-	mgo "gopkg.in/mgo.v2"
+	"github.com/hjkelly/zbbapi/models"
 	"gopkg.in/mgo.v2/bson"
 )
 
-// Delete uses the datastore to remove this ID, if it exists.
-func Delete(id string) error {
-	ds := newDatastore()
-	err := ds.C().Remove(bson.M{
-		"_id": uuid.FromStringOrNil(id),
-	})
+// Delete moves the Category with this ID to the trash, if it exists. If expectedVersion isn't common.AnyVersion, the Category must still be at that version.
+//
+// A Category that plans or budgets still refer to can't be deleted, unless reassignTo names another Category for those references to point at instead. Its subcategories are moved up to its own parent.
+func Delete(caller common.Caller, id string, expectedVersion int, reassignTo string) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
 	if err != nil {
-		if err == mgo.ErrNotFound {
-			return common.NotFoundErr
+		return err
+	}
+	if expectedVersion != common.AnyVersion && expectedVersion != current.Version {
+		return common.NewStaleError(current)
+	}
+
+	// Make sure nothing will be left referring to it.
+	currentID := current.SafeID()
+	if len(reassignTo) > 0 {
+		target, err := ds.reassignTarget(currentID, reassignTo)
+		if err != nil {
+			return err
+		}
+		err = reassign(caller, currentID, target)
+		if err != nil {
+			return err
 		}
+	} else {
+		referenced, err := isReferenced(caller, currentID)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return common.InUseErr
+		}
+	}
+
+	// Its subcategories move up a level, to its own parent.
+	children := make([]models.Category, 0)
+	err = ds.find(bson.M{
+		"parentid": currentID,
+	}).All(&children)
+	if err != nil {
 		return err
 	}
-	return nil
+	for _, child := range children {
+		moved := child
+		moved.ParentID = current.ParentID
+		moved.SetModificationTimestamp()
+		moved.IncrementVersion()
+		err = ds.save(child, moved, models.RevisionUpdate)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save it as a new version, so it can be restored later.
+	result := *current
+	result.MarkDeleted()
+	result.SetModificationTimestamp()
+	result.IncrementVersion()
+	return ds.save(*current, result, models.RevisionDelete)
+}
+
+// Makes sure the Category that references are being moved to exists, and isn't the one being deleted.
+func (ds datastore) reassignTarget(id models.SafeUUID, reassignTo string) (models.SafeUUID, error) {
+	notFoundErr := common.NewValidationError("reassignTo", common.NonexistentRefCode, "reassign")
+	target, err := models.SafeUUID(strings.TrimSpace(reassignTo)).GetValidated()
+	if err != nil {
+		return "", common.AddValidationContext(err, "reassignTo")
+	}
+	if target == id {
+		return "", notFoundErr
+	}
+	_, err = ds.findID(string(target))
+	if err != nil {
+		if err == common.NotFoundErr {
+			return "", notFoundErr
+		}
+		return "", err
+	}
+	return target, nil
 }
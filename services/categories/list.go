@@ -1,17 +1,28 @@
 package categories
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
 	"gopkg.in/mgo.v2/bson"
 )
 
-// List returns all Categories from the database.
-func List() ([]models.Category, error) {
-	ds := newDatastore()
+// List returns all Categories from the database, except those in the trash.
+func List(caller common.Caller) ([]models.Category, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 	results := make([]models.Category, 0)
-	err := ds.C().Find(bson.M{}).All(&results)
+	err := ds.find(common.NotDeleted(bson.M{})).Sort("sortorder", "name").All(&results)
 	if err != nil {
 		return nil, err
 	}
 	return results, nil
 }
+
+// Tree returns all Categories arranged under their parents.
+func Tree(caller common.Caller) ([]models.CategoryNode, error) {
+	all, err := List(caller)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildCategoryTree(all), nil
+}
@@ -0,0 +1,27 @@
+package categories
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// PatchID finds the current Category by ID, applies a JSON merge patch (RFC 7396) to it, and saves it again the same way UpdateID would.
+func PatchID(caller common.Caller, id string, patch []byte, expectedVersion int) (*models.Category, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+
+	// Make sure the one we're patching exists.
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the patch to a copy of the stored document so it can be validated like a full update.
+	input := models.Category{}
+	err = common.ApplyMergePatch(current, patch, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.update(*current, input, expectedVersion)
+}
@@ -0,0 +1,100 @@
+package categories
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	uuid "github.com/satori/go.uuid"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Referrer is a kind of document that can refer to categories, like plans or budgets. Those packages register one when they load, so we can look after their references without importing them.
+type Referrer struct {
+	// IsReferenced says whether any of the caller's documents still refer to the category.
+	IsReferenced func(caller common.Caller, categoryID models.SafeUUID) (bool, error)
+	// Reassign points every reference the caller's documents have to one category at another instead.
+	Reassign func(caller common.Caller, from, to models.SafeUUID) error
+}
+
+var referrers []Referrer
+
+// RegisterReferrer lets us check for (and reassign) references to a category before it's deleted.
+func RegisterReferrer(referrer Referrer) {
+	referrers = append(referrers, referrer)
+}
+
+// CheckRefs makes sure each category the line items refer to exists.
+func CheckRefs(caller common.Caller, items []models.LineItemRef) error {
+	found, err := findRefs(caller, items)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, ref := range items {
+		if len(ref.Item.CategoryID) > 0 && found[ref.Item.CategoryID] == nil {
+			errs = append(errs, common.NewValidationError(ref.FieldName+".categoryId", common.NonexistentRefCode, "category"))
+		}
+	}
+	return common.CombineErrors(errs...)
+}
+
+// Expand embeds the category each line item refers to, for clients that asked for ?expand=category.
+func Expand(caller common.Caller, items []models.LineItemRef) error {
+	found, err := findRefs(caller, items)
+	if err != nil {
+		return err
+	}
+	for _, ref := range items {
+		ref.Item.Category = found[ref.Item.CategoryID]
+	}
+	return nil
+}
+
+// Fetches every category the line items refer to, all at once.
+func findRefs(caller common.Caller, items []models.LineItemRef) (map[models.SafeUUID]*models.Category, error) {
+	found := map[models.SafeUUID]*models.Category{}
+	ids := make([]uuid.UUID, 0)
+	for _, ref := range items {
+		if len(ref.Item.CategoryID) > 0 {
+			ids = append(ids, uuid.FromStringOrNil(string(ref.Item.CategoryID)))
+		}
+	}
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	results := make([]models.Category, 0)
+	err := ds.find(common.NotDeleted(bson.M{
+		"_id": bson.M{"$in": ids},
+	})).All(&results)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range results {
+		found[results[idx].SafeID()] = &results[idx]
+	}
+	return found, nil
+}
+
+// Says whether any registered referrer still refers to the category.
+func isReferenced(caller common.Caller, id models.SafeUUID) (bool, error) {
+	for _, referrer := range referrers {
+		referenced, err := referrer.IsReferenced(caller, id)
+		if err != nil || referenced {
+			return referenced, err
+		}
+	}
+	return false, nil
+}
+
+// Points every registered referrer's references to one category at another instead.
+func reassign(caller common.Caller, from, to models.SafeUUID) error {
+	for _, referrer := range referrers {
+		err := referrer.Reassign(caller, from, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package categories
+
+import (
+	"encoding/json"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
+)
+
+// Revert saves the Category as it was at an earlier version, going through the same validation as any update. If expectedVersion isn't common.AnyVersion, the Category must still be at that version.
+func Revert(caller common.Caller, id string, version int, expectedVersion int) (*models.Category, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := revisions.Retrieve(caller, resourceName, current.ID.String(), version)
+	if err != nil {
+		return nil, err
+	}
+	input := models.Category{}
+	err = json.Unmarshal(revision.Snapshot, &input)
+	if err != nil {
+		return nil, err
+	}
+	return ds.revise(*current, input, expectedVersion, models.RevisionRevert)
+}
+
+// Revisions lists the Category's revisions, newest first.
+func Revisions(caller common.Caller, id string) ([]models.Revision, error) {
+	return revisions.List(caller, resourceName, id)
+}
+
+// RetrieveRevision shows the Category as it was at an earlier version.
+func RetrieveRevision(caller common.Caller, id string, version int) (*models.Revision, error) {
+	return revisions.Retrieve(caller, resourceName, id, version)
+}
@@ -3,38 +3,49 @@ package categories
 import (
 	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
-	uuid "github.com/satori/go.uuid"
-	mgo "gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
 )
 
-// UpdateID finds the current Category by ID, updates all its user-updatable fields, and saves it again.
-func UpdateID(id string, input models.Category) (*models.Category, error) {
-	ds := newDatastore()
+// UpdateID finds the current Category by ID, updates all its user-updatable fields, and saves it again. If expectedVersion isn't common.AnyVersion, the Category must still be at that version.
+func UpdateID(caller common.Caller, id string, input models.Category, expectedVersion int) (*models.Category, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 
 	// Make sure the one we're updating exists.
-	current := models.Category{}
-	err := ds.C().Find(bson.M{
-		"_id": uuid.FromStringOrNil(id),
-	}).One(&current)
+	current, err := ds.findID(id)
 	if err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, common.NotFoundErr
-		}
 		return nil, err
 	}
 
+	return ds.update(*current, input, expectedVersion)
+}
+
+// Validates the input, uses it to update the current Category, and saves the result as long as nobody else saved a newer version first.
+func (ds datastore) update(current, input models.Category, expectedVersion int) (*models.Category, error) {
+	return ds.revise(current, input, expectedVersion, models.RevisionUpdate)
+}
+
+// Does the work of update, recording the change as the given kind of revision.
+func (ds datastore) revise(current, input models.Category, expectedVersion int, action string) (*models.Category, error) {
+	if expectedVersion != common.AnyVersion && expectedVersion != current.Version {
+		return nil, common.NewStaleError(current)
+	}
+
 	// Validate the input and use it to update the current data.
 	input = sanitize(input)
-	err = validate(input)
+	err := validate(input)
+	if err != nil {
+		return nil, err
+	}
+	input.ParentID, err = validateParent(ds.caller, current.SafeID(), input.ParentID)
 	if err != nil {
 		return nil, err
 	}
 	result := getUpdated(current, input)
 	result.SetModificationTimestamp()
+	result.IncrementVersion()
 
-	// Update the database with our new result.
-	err = ds.C().UpdateId(result.ID, result)
+	// Update the database with our new result, but only if it's still at the version we started from.
+	err = ds.save(current, result, action)
 	if err != nil {
 		return nil, err
 	}
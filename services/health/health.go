@@ -0,0 +1,71 @@
+package health
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// check is something the server needs before it can handle requests.
+type check struct {
+	name string
+	run  func() error
+}
+
+// readinessChecks are run, in order, each time someone asks whether we're ready.
+var readinessChecks = []check{
+	{"mongo", common.PingMongo},
+	{"migrations", checkMigrations},
+}
+
+// Alive reports that the server is running. It doesn't check anything else, so a struggling database can't get the server restarted.
+func Alive() models.Health {
+	return models.Health{Status: models.HealthOK, Checks: []models.HealthCheck{}}
+}
+
+// Ready runs every readiness check, and reports the server as ready only if they all pass.
+func Ready() models.Health {
+	return run(readinessChecks)
+}
+
+func run(checks []check) models.Health {
+	health := models.Health{Status: models.HealthOK, Checks: make([]models.HealthCheck, 0, len(checks))}
+	for _, c := range checks {
+		result := runCheck(c)
+		if result.Status != models.HealthOK {
+			health.Status = models.HealthUnavailable
+		}
+		health.Checks = append(health.Checks, result)
+	}
+	return health
+}
+
+// Runs a single check and times it. A check that panics fails, rather than taking the whole report down with it.
+func runCheck(c check) (result models.HealthCheck) {
+	start := time.Now()
+	result = models.HealthCheck{Name: c.name, Status: models.HealthOK}
+	defer func() {
+		if p := recover(); p != nil {
+			result.Status = models.HealthUnavailable
+			result.Error = "The check panicked."
+		}
+		result.LatencyMs = time.Since(start).Seconds() * 1000
+	}()
+	err := c.run()
+	if err != nil {
+		result.Status = models.HealthUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Passes once every migration has been applied. Applying them, and retrying any that failed, is left to the server's startup, so asking whether we're ready never changes the database.
+func checkMigrations() error {
+	if pending := common.PendingMigrations(); len(pending) > 0 {
+		return errors.New("Not yet applied: " + strings.Join(pending, ", "))
+	}
+	return nil
+}
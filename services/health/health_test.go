@@ -0,0 +1,49 @@
+package health
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/stretchr/testify/assert"
+	mgo "gopkg.in/mgo.v2"
+)
+
+func TestRun(t *testing.T) {
+	passing := check{"passing", func() error { return nil }}
+	failing := check{"failing", func() error { return errors.New("can't connect") }}
+	panicking := check{"panicking", func() error { panic("oops") }}
+
+	health := run([]check{passing})
+	assert.Equal(t, models.HealthOK, health.Status)
+	assert.Len(t, health.Checks, 1)
+	assert.Equal(t, models.HealthOK, health.Checks[0].Status)
+	assert.Empty(t, health.Checks[0].Error)
+
+	health = run([]check{passing, failing, panicking})
+	assert.Equal(t, models.HealthUnavailable, health.Status, "any failing check should make us unavailable")
+	assert.Len(t, health.Checks, 3, "every check should be reported")
+	assert.Equal(t, models.HealthOK, health.Checks[0].Status)
+	assert.Equal(t, "can't connect", health.Checks[1].Error)
+	assert.Equal(t, models.HealthUnavailable, health.Checks[2].Status)
+	for _, result := range health.Checks {
+		assert.True(t, result.LatencyMs >= 0, "CASE: %s", result.Name)
+	}
+}
+
+func TestAlive(t *testing.T) {
+	assert.Equal(t, models.HealthOK, Alive().Status)
+}
+
+func TestCheckMigrationsOnlyReports(t *testing.T) {
+	applied := false
+	common.RegisterMigration("health/never-applied", func(*mgo.Session) error {
+		applied = true
+		return nil
+	})
+	err := checkMigrations()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "health/never-applied")
+	assert.False(t, applied, "checking readiness shouldn't apply migrations")
+}
@@ -0,0 +1,33 @@
+package households
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/users"
+)
+
+// Create starts a Household with the caller as its owner.
+func Create(caller common.Caller, input models.Household) (*models.Household, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+	user, err := users.Retrieve(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.ID = models.NewSafeUUID()
+	input.SetCreationTimestamp()
+	input.SetInitialVersion()
+	input.Members = []models.Member{{UserID: user.ID, Email: user.Email, Role: models.RoleOwner, Joined: input.Created}}
+	input.Invitations = []models.Invitation{}
+
+	ds := newDatastore()
+	defer ds.session.Close()
+	err = ds.C().Insert(input)
+	if err != nil {
+		return nil, err
+	}
+	return &input, nil
+}
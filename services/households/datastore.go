@@ -0,0 +1,105 @@
+package households
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type datastore struct {
+	session *mgo.Session
+}
+
+func init() {
+	common.RegisterMigration("households/membership-indexes", ensureIndexes)
+}
+
+func newDatastore() *datastore {
+	return &datastore{common.GetMongoSession()}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "household").C("households"))
+}
+
+// Lets us find the households someone belongs to, or was invited to, without scanning them all.
+func ensureIndexes(session *mgo.Session) error {
+	ds := datastore{session}
+	for _, key := range []string{"members.userid", "invitations.email"} {
+		err := ds.C().EnsureIndex(mgo.Index{Key: []string{key}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findID fetches a single Household by ID, translating a missing document into our NotFoundErr.
+func (ds datastore) findID(id string) (*models.Household, error) {
+	result := new(models.Household)
+	err := ds.C().FindId(id).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// findMembership fetches a Household the user belongs to, along with their role in it. To anyone else, it doesn't exist.
+func (ds datastore) findMembership(id, userID string) (*models.Household, string, error) {
+	household, err := ds.findID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	role, ok := household.RoleOf(models.SafeUUID(userID))
+	if !ok {
+		return nil, "", common.NotFoundErr
+	}
+	return household, role, nil
+}
+
+// findOwnership fetches a Household the user belongs to, and makes sure they own it.
+func (ds datastore) findOwnership(id, userID string) (*models.Household, error) {
+	household, role, err := ds.findMembership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.RoleOwner {
+		return nil, common.ForbiddenErr
+	}
+	return household, nil
+}
+
+// save replaces the current Household with the result, but only if it's still at the version we started from.
+func (ds datastore) save(current models.Household, result *models.Household) error {
+	result.SetModificationTimestamp()
+	result.IncrementVersion()
+	err := ds.C().Update(common.VersionSelector(current.ID, current.Version), result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			latest, err := ds.findID(string(current.ID))
+			if err != nil {
+				return err
+			}
+			return common.NewStaleError(latest)
+		}
+		return err
+	}
+	return nil
+}
+
+// Fails with a StaleError if the client expected a different version than the current one.
+func checkVersion(current *models.Household, expectedVersion int) error {
+	if expectedVersion != common.AnyVersion && expectedVersion != current.Version {
+		return common.NewStaleError(current)
+	}
+	return nil
+}
+
+// Selects the households a user belongs to.
+func memberSelector(userID string) bson.M {
+	return bson.M{"members.userid": userID}
+}
@@ -0,0 +1,110 @@
+package households
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/users"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Invite asks someone to join the Household with a role. Only owners can invite people.
+func Invite(caller common.Caller, id string, input models.Invitation) (*models.Invitation, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+	ds := newDatastore()
+	defer ds.session.Close()
+	current, err := ds.findOwnership(id, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.ID = models.NewSafeUUID()
+	input.InvitedBy = models.SafeUUID(caller.UserID)
+	input.Invited = time.Now()
+	result := *current
+	result.Invitations = append([]models.Invitation{}, current.Invitations...)
+	err = result.Invite(input)
+	if err != nil {
+		return nil, err
+	}
+	err = ds.save(*current, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &input, nil
+}
+
+// RevokeInvitation withdraws an invitation that hasn't been accepted yet. Only owners can revoke invitations.
+func RevokeInvitation(caller common.Caller, id, invitationID string, expectedVersion int) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	current, err := ds.findOwnership(id, caller.UserID)
+	if err != nil {
+		return err
+	}
+	err = checkVersion(current, expectedVersion)
+	if err != nil {
+		return err
+	}
+	result := *current
+	result.Invitations = append([]models.Invitation{}, current.Invitations...)
+	err = result.Revoke(models.SafeUUID(invitationID))
+	if err != nil {
+		return err
+	}
+	return ds.save(*current, &result)
+}
+
+// Accept makes the caller a member of the Household, with the role they were invited with.
+func Accept(caller common.Caller, id, invitationID string) (*models.Household, error) {
+	user, err := users.Retrieve(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	ds := newDatastore()
+	defer ds.session.Close()
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+	result := *current
+	result.Members = append([]models.Member{}, current.Members...)
+	result.Invitations = append([]models.Invitation{}, current.Invitations...)
+	err = result.Accept(models.SafeUUID(invitationID), *user)
+	if err != nil {
+		return nil, err
+	}
+	err = ds.save(*current, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListInvitations returns the invitations waiting for the caller to accept them.
+func ListInvitations(caller common.Caller) ([]models.PendingInvitation, error) {
+	user, err := users.Retrieve(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	ds := newDatastore()
+	defer ds.session.Close()
+	invitedTo := make([]models.Household, 0)
+	err = ds.C().Find(bson.M{"invitations.email": user.Email}).All(&invitedTo)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]models.PendingInvitation, 0, len(invitedTo))
+	for _, household := range invitedTo {
+		for _, invitation := range household.Invitations {
+			if invitation.Email == user.Email {
+				results = append(results, models.PendingInvitation{Invitation: invitation, HouseholdID: household.ID, HouseholdName: household.Name})
+			}
+		}
+	}
+	return results, nil
+}
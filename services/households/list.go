@@ -0,0 +1,34 @@
+package households
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// List returns the Households the caller belongs to.
+func List(caller common.Caller) ([]models.Household, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	results := make([]models.Household, 0)
+	err := ds.C().Find(memberSelector(caller.UserID)).Sort("name").All(&results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Retrieve fetches a single Household, if the caller belongs to it.
+func Retrieve(caller common.Caller, id string) (*models.Household, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	household, _, err := ds.findMembership(id, caller.UserID)
+	return household, err
+}
+
+// RoleOf returns the user's role in the Household. If they don't belong to it, it's reported as not found.
+func RoleOf(id, userID string) (string, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	_, role, err := ds.findMembership(id, userID)
+	return role, err
+}
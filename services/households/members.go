@@ -0,0 +1,59 @@
+package households
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// ChangeRole gives a member of the Household a different role. Only owners can change roles.
+func ChangeRole(caller common.Caller, id, userID string, input models.RoleChange, expectedVersion int) (*models.Household, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+	ds := newDatastore()
+	defer ds.session.Close()
+	current, err := ds.findOwnership(id, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkVersion(current, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	result := *current
+	result.Members = append([]models.Member{}, current.Members...)
+	err = result.SetRole(models.SafeUUID(userID), input.Role)
+	if err != nil {
+		return nil, err
+	}
+	err = ds.save(*current, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveMember takes someone out of the Household. Owners can remove anyone, and anyone can leave.
+func RemoveMember(caller common.Caller, id, userID string, expectedVersion int) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	current, role, err := ds.findMembership(id, caller.UserID)
+	if err != nil {
+		return err
+	}
+	if role != models.RoleOwner && userID != caller.UserID {
+		return common.ForbiddenErr
+	}
+	err = checkVersion(current, expectedVersion)
+	if err != nil {
+		return err
+	}
+	result := *current
+	result.Members = append([]models.Member{}, current.Members...)
+	err = result.RemoveMember(models.SafeUUID(userID))
+	if err != nil {
+		return err
+	}
+	return ds.save(*current, &result)
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Begin claims the key for the request with this fingerprint, or finds the response to replay.
+func (MongoStore) Begin(key, fingerprint string) (*Record, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	for {
+		err := ds.C().Insert(Record{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Created:     time.Now(),
+		})
+		if err == nil {
+			return nil, nil
+		}
+		if !mgo.IsDup(err) {
+			return nil, err
+		}
+
+		// Someone already used this key; see what they did with it.
+		existing := Record{}
+		err = ds.C().FindId(key).One(&existing)
+		if err == mgo.ErrNotFound {
+			// It expired between our insert and lookup, so try claiming it again.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if existing.isExpired() {
+			err = ds.C().Remove(bson.M{"_id": key, "created": existing.Created})
+			if err != nil && err != mgo.ErrNotFound {
+				return nil, err
+			}
+			continue
+		}
+		if existing.Fingerprint != fingerprint {
+			return nil, common.IdempotencyKeyReusedErr
+		}
+		if !existing.Completed {
+			return nil, common.IdempotencyKeyInProgressErr
+		}
+		return &existing, nil
+	}
+}
@@ -0,0 +1,46 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/hjkelly/zbbapi/config"
+)
+
+// Store keeps track of Idempotency-Keys and the responses to the requests that used them.
+type Store interface {
+	// Begin claims the key for the request with this fingerprint. If the key was already used for the same request and that request finished, the stored Record is returned so its response can be replayed; otherwise the result is nil and the caller should handle the request, then call Finish or Release.
+	Begin(key, fingerprint string) (*Record, error)
+	// Finish stores the response to the request that claimed this key, so retries get the same response.
+	Finish(key string, status int, etag string, body []byte) error
+	// Release forgets the key without storing a response, so the request can be retried for real. This is for failures on our end that the client shouldn't be stuck with.
+	Release(key string) error
+}
+
+// MongoStore keeps keys in Mongo, so a retry can be sent to any instance.
+type MongoStore struct{}
+
+// Record is what we remember about a request made with an Idempotency-Key: which request it was, and (once it's done) the response we gave.
+type Record struct {
+	Key         string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status"`
+	ETag        string    `bson:"etag,omitempty"`
+	Body        []byte    `bson:"body"`
+	Created     time.Time `bson:"created"`
+}
+
+// Fingerprint identifies a request by its method, path, and body, so a key can't be replayed for a different request.
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Reports whether the record is old enough that the key can be used again. Mongo's TTL monitor only runs periodically, so we can't count on it for this.
+func (record Record) isExpired() bool {
+	return time.Since(record.Created) > config.GetConfig().IdempotencyWindow
+}
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	mgo "gopkg.in/mgo.v2"
+)
+
+type datastore struct {
+	session *mgo.Session
+}
+
+func init() {
+	common.RegisterMigration("idempotency/expiration-index", ensureIndexes)
+}
+
+func newDatastore() *datastore {
+	return &datastore{common.GetMongoSession()}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "idempotency").C("keys"))
+}
+
+// Lets Mongo expire keys on its own once they're outside the idempotency window.
+func ensureIndexes(session *mgo.Session) error {
+	ds := datastore{session}
+	return ds.C().EnsureIndex(mgo.Index{
+		Key:         []string{"created"},
+		ExpireAfter: config.GetConfig().IdempotencyWindow,
+	})
+}
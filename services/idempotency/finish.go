@@ -0,0 +1,26 @@
+package idempotency
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Finish stores the response to the request that claimed this key.
+func (MongoStore) Finish(key string, status int, etag string, body []byte) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	return ds.C().UpdateId(key, bson.M{
+		"$set": bson.M{
+			"completed": true,
+			"status":    status,
+			"etag":      etag,
+			"body":      body,
+		},
+	})
+}
+
+// Release forgets the key without storing a response.
+func (MongoStore) Release(key string) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	return ds.C().RemoveId(key)
+}
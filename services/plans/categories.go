@@ -0,0 +1,67 @@
+package plans
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/categories"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	categories.RegisterReferrer(categories.Referrer{
+		IsReferenced: isCategoryReferenced,
+		Reassign:     reassignCategory,
+	})
+}
+
+// ExpandCategories embeds the category each of the Plans' line items refers to.
+func ExpandCategories(caller common.Caller, plans ...*models.Plan) error {
+	items := make([]models.LineItemRef, 0)
+	for _, plan := range plans {
+		items = append(items, plan.LineItems()...)
+	}
+	return categories.Expand(caller, items)
+}
+
+// Matches any Plan with a line item referring to the category, including those in the trash.
+func categorySelector(categoryID models.SafeUUID) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"incomes.nameandamount.categoryid": categoryID},
+		{"bills.nameandamount.categoryid": categoryID},
+		{"expenses.nameandamount.categoryid": categoryID},
+		{"savings.nameandamount.categoryid": categoryID},
+	}}
+}
+
+func isCategoryReferenced(caller common.Caller, categoryID models.SafeUUID) (bool, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	count, err := ds.find(categorySelector(categoryID)).Limit(1).Count()
+	return count > 0, err
+}
+
+// Points each line item referring to one category at another instead, saving each Plan as a new version. Trashed Plans are included, so they still make sense if they're restored.
+func reassignCategory(caller common.Caller, from, to models.SafeUUID) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	results := make([]models.Plan, 0)
+	err := ds.find(categorySelector(from)).All(&results)
+	if err != nil {
+		return err
+	}
+	for _, current := range results {
+		result := current
+		for _, ref := range result.LineItems() {
+			if ref.Item.CategoryID == from {
+				ref.Item.CategoryID = to
+			}
+		}
+		result.SetModificationTimestamp()
+		result.IncrementVersion()
+		err = ds.save(current, &result, models.RevisionUpdate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
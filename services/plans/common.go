@@ -1,12 +1,23 @@
 package plans
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/categories"
 )
 
 // Make sure this Plan has input sufficient enough to be saved.
-func getValidated(input models.Plan) (models.Plan, error) {
-	return input.GetValidated()
+func getValidated(caller common.Caller, input models.Plan) (models.Plan, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return input, err
+	}
+	// Only now that we know the category IDs are well-formed can we look them up.
+	err = categories.CheckRefs(caller, input.LineItems())
+	if err != nil {
+		return models.Plan{}, err
+	}
+	return input, nil
 }
 
 // Returns the updated Plan, which is the current Plan updated with the input data for the update.
@@ -16,5 +27,7 @@ func getUpdated(current, input models.Plan) models.Plan {
 	current.Expenses = input.Expenses
 	current.Savings = input.Savings
 	current.SavingsStrategy = input.SavingsStrategy
+	current.Totals = input.Totals
+	current.Warnings = input.Warnings
 	return current
 }
@@ -2,17 +2,73 @@ package plans
 
 import (
 	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
 	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
+// resourceName is what we call Plans in URLs and revision history.
+const resourceName = "plans"
+
+// datastore works with the caller's tenant's Plans only.
 type datastore struct {
 	session *mgo.Session
+	caller  common.Caller
+}
+
+func newDatastore(caller common.Caller) *datastore {
+	return &datastore{common.GetMongoSession(), caller}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "plan").C("plans"))
+}
+
+// scoped narrows a selector to the caller's tenant.
+func (ds datastore) scoped(selector bson.M) bson.M {
+	return common.ForTenant(ds.caller.TenantID, selector)
+}
+
+// find queries the caller's tenant's Plans.
+func (ds datastore) find(selector bson.M) *common.Query {
+	return ds.C().Find(ds.scoped(selector))
+}
+
+// findID fetches a single Plan by ID, translating a missing (or trashed) document into our NotFoundErr.
+func (ds datastore) findID(id string) (*models.Plan, error) {
+	result := new(models.Plan)
+	err := ds.find(common.NotDeleted(bson.M{
+		"_id": id,
+	})).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
 }
 
-func newDatastore() *datastore {
-	return &datastore{common.GetMongoSession()}
+// conflict explains why a versioned update didn't match anything: either the Plan is gone, or someone else saved a newer version.
+func (ds datastore) conflict(id string) error {
+	latest, err := ds.findID(id)
+	if err != nil {
+		return err
+	}
+	return common.NewStaleError(latest)
 }
 
-func (ds datastore) C() *mgo.Collection {
-	return ds.session.DB("plan").C("plans")
+// save replaces the current Plan with the result, but only if it's still at the version we started from, and records the change as a revision. The result is attributed to the caller.
+func (ds datastore) save(current models.Plan, result *models.Plan, action string) error {
+	result.SetModifier(ds.caller.UserID)
+	err := ds.C().Update(ds.scoped(common.VersionSelector(current.ID, current.Version)), result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return ds.conflict(string(current.ID))
+		}
+		return err
+	}
+	revisions.Record(ds.caller, resourceName, string(result.ID), result.Version, action, current, *result)
+	return nil
 }
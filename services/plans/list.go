@@ -1,15 +1,17 @@
 package plans
 
 import (
+	"github.com/hjkelly/zbbapi/common"
 	"github.com/hjkelly/zbbapi/models"
 	"gopkg.in/mgo.v2/bson"
 )
 
-// List returns all Plans from the database.
-func List() ([]models.Plan, error) {
-	ds := newDatastore()
+// List returns all Plans from the database, except those in the trash.
+func List(caller common.Caller) ([]models.Plan, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
 	results := make([]models.Plan, 0)
-	err := ds.C().Find(bson.M{}).All(&results)
+	err := ds.find(common.NotDeleted(bson.M{})).All(&results)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,22 @@
+package plans
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// Overwrite saves the Plan exactly as given, without validating it or touching its timestamps and version, but only if it's still at currentVersion; otherwise it fails the way a stale update would, so nobody else's change is lost. It's meant for undoing changes, like rolling back a batch, so any revisions after its version are forgotten.
+func Overwrite(caller common.Caller, doc models.Plan, currentVersion int) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	doc.SetTenant(caller.TenantID)
+	err := ds.C().Update(ds.scoped(common.VersionSelector(doc.ID, currentVersion)), doc)
+	if err == mgo.ErrNotFound {
+		return ds.conflict(string(doc.ID))
+	} else if err != nil {
+		return err
+	}
+	return revisions.DiscardAfter(caller, resourceName, string(doc.ID), doc.Version)
+}
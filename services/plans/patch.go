@@ -0,0 +1,32 @@
+package plans
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// PatchID finds the current Plan by ID, applies a JSON merge patch (RFC 7396) to it, and saves it again the same way UpdateID would.
+func PatchID(caller common.Caller, id string, patch []byte, expectedVersion int) (*models.Plan, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+
+	// Make sure the one we're patching exists.
+	current, err := ds.findID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := patched(*current, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.update(*current, input, expectedVersion)
+}
+
+// Applies the patch to a copy of the stored document so it can be validated like a full update.
+func patched(current models.Plan, patch []byte) (models.Plan, error) {
+	input := models.Plan{}
+	err := common.ApplyMergePatch(current, patch, &input)
+	return input, err
+}
@@ -0,0 +1,91 @@
+package plans
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ListTrash returns all Plans that were deleted but haven't been purged yet.
+func ListTrash(caller common.Caller) ([]models.Plan, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	results := make([]models.Plan, 0)
+	err := ds.find(common.InTrash(bson.M{})).All(&results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Restore takes the Plan with this ID back out of the trash.
+func Restore(caller common.Caller, id string) (*models.Plan, error) {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	current := new(models.Plan)
+	err := ds.find(common.InTrash(bson.M{
+		"_id": id,
+	})).One(current)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+
+	result := *current
+	result.Restore()
+	result.SetModificationTimestamp()
+	result.IncrementVersion()
+	err = ds.save(*current, &result, models.RevisionRestore)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Purge permanently removes the Plan with this ID, whether or not it's in the trash. It's meant for undoing a create, like rolling back a batch, so its revisions are forgotten too.
+func Purge(caller common.Caller, id string) error {
+	ds := newDatastore(caller)
+	defer ds.session.Close()
+	err := ds.C().Remove(ds.scoped(bson.M{
+		"_id": id,
+	}))
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return common.NotFoundErr
+		}
+		return err
+	}
+	return revisions.DiscardAfter(caller, resourceName, id, 0)
+}
+
+// PurgeDeletedBefore permanently removes Plans that were moved to the trash before the cutoff, and returns them.
+func PurgeDeletedBefore(cutoff time.Time) ([]models.Plan, error) {
+	// The sweep covers every tenant's trash at once.
+	ds := newDatastore(common.Caller{})
+	defer ds.session.Close()
+	expired := make([]models.Plan, 0)
+	err := ds.C().Find(common.DeletedBefore(cutoff)).All(&expired)
+	if err != nil {
+		return nil, err
+	}
+	purged := make([]models.Plan, 0, len(expired))
+	for _, doc := range expired {
+		selector := common.DeletedBefore(cutoff)
+		selector["_id"] = doc.ID
+		err = ds.C().Remove(selector)
+		if err == mgo.ErrNotFound {
+			// It was restored since we looked.
+			continue
+		} else if err != nil {
+			return purged, err
+		}
+		purged = append(purged, doc)
+	}
+	return purged, nil
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/hjkelly/zbbapi/config"
+)
+
+// Store keeps track of each client's token bucket. Take spends a token from the bucket for this key if there's one left; if not, it says how long until there will be.
+type Store interface {
+	Take(key string, limit config.RateLimit, now time.Time) (bool, time.Duration, error)
+}
+
+// bucket holds a client's remaining tokens as of when it was last updated. It refills continuously, up to the limit's burst size.
+type bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// A client we haven't seen yet starts with a full bucket.
+func fullBucket(limit config.RateLimit, now time.Time) bucket {
+	return bucket{Tokens: float64(limit.Requests), Updated: now}
+}
+
+// Refills the bucket for the time that's passed, then tries to spend a token. It returns the updated bucket, whether a token was spent, and if not, how long until one will be available.
+func (b bucket) take(limit config.RateLimit, now time.Time) (bucket, bool, time.Duration) {
+	perSecond := float64(limit.Requests) / limit.Per.Seconds()
+	// Clocks on different instances can disagree a little; never refill for negative time.
+	elapsed := math.Max(0, now.Sub(b.Updated).Seconds())
+	tokens := math.Min(float64(limit.Requests), b.Tokens+elapsed*perSecond)
+	if tokens >= 1 {
+		return bucket{Tokens: tokens - 1, Updated: now}, true, 0
+	}
+	wait := time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	return bucket{Tokens: tokens, Updated: now}, false, wait
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var limit = config.RateLimit{Requests: 2, Per: 10 * time.Second}
+
+func TestBucketTake(t *testing.T) {
+	start := time.Now()
+	b := fullBucket(limit, start)
+
+	b, allowed, _ := b.take(limit, start)
+	assert.True(t, allowed)
+	b, allowed, _ = b.take(limit, start)
+	assert.True(t, allowed)
+	b, allowed, wait := b.take(limit, start)
+	assert.False(t, allowed, "the burst should be used up")
+	assert.Equal(t, 5*time.Second, wait, "one token refills every five seconds")
+
+	_, allowed, _ = b.take(limit, start.Add(5*time.Second))
+	assert.True(t, allowed)
+}
+
+func TestBucketRefillsOnlyToBurst(t *testing.T) {
+	start := time.Now()
+	b := bucket{Tokens: 0, Updated: start}
+	b, _, _ = b.take(limit, start.Add(time.Hour))
+	assert.Equal(t, 1.0, b.Tokens, "it should refill to the burst size, then spend one")
+}
+
+func TestBucketIgnoresClockSkew(t *testing.T) {
+	start := time.Now()
+	b := bucket{Tokens: 0.5, Updated: start}
+	b, allowed, _ := b.take(limit, start.Add(-time.Minute))
+	assert.False(t, allowed)
+	assert.Equal(t, 0.5, b.Tokens)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	for idx := 0; idx < limit.Requests; idx++ {
+		allowed, _, err := store.Take("a", limit, now)
+		assert.Nil(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, wait, _ := store.Take("a", limit, now)
+	assert.False(t, allowed)
+	assert.True(t, wait > 0)
+
+	allowed, _, _ = store.Take("b", limit, now)
+	assert.True(t, allowed, "each key should have its own bucket")
+}
+
+func TestMemoryStorePrunes(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.Take("idle", limit, now)
+	later := now.Add(limit.Per)
+	for idx := 1; idx < pruneEvery; idx++ {
+		store.Take("busy", limit, later)
+	}
+	_, ok := store.buckets["idle"]
+	assert.False(t, ok, "a bucket that's had time to refill should be forgotten")
+}
+
+func TestMemoryStorePrunesEachBucketByItsOwnLimit(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	slow := config.RateLimit{Requests: 5, Per: time.Hour}
+	store.Take("ip:1.2.3.4", slow, now)
+	later := now.Add(limit.Per)
+	for idx := 1; idx < pruneEvery; idx++ {
+		store.Take("busy", limit, later)
+	}
+	_, ok := store.buckets["ip:1.2.3.4"]
+	assert.True(t, ok, "a bucket that's still refilling under its own limit should be kept")
+}
@@ -0,0 +1,6 @@
+package ratelimit
+
+import "errors"
+
+// errContended means other requests for the same client kept changing its bucket before we could.
+var errContended = errors.New("the rate limit bucket kept changing before it could be updated")
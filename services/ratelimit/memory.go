@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hjkelly/zbbapi/config"
+)
+
+// How many times buckets are taken from between clearing out the ones that have refilled.
+const pruneEvery = 1000
+
+// MemoryStore keeps buckets in this process, which is all a single instance needs.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	buckets map[string]memoryBucket
+	takes   int
+}
+
+// memoryBucket remembers the window of the limit a bucket was taken under, since each class of request refills at its own pace.
+type memoryBucket struct {
+	bucket
+	Per time.Duration
+}
+
+// NewMemoryStore starts with every client's bucket full.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]memoryBucket{}}
+}
+
+// Take spends a token from the key's bucket, if there's one left.
+func (store *MemoryStore) Take(key string, limit config.RateLimit, now time.Time) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current, ok := store.buckets[key]
+	if !ok {
+		current.bucket = fullBucket(limit, now)
+	}
+	next, allowed, wait := current.take(limit, now)
+	store.buckets[key] = memoryBucket{bucket: next, Per: limit.Per}
+
+	store.takes++
+	if store.takes%pruneEvery == 0 {
+		store.prune(now)
+	}
+	return allowed, wait, nil
+}
+
+// Forgets buckets that have had time to refill completely under their own limit, since a new bucket would be the same. This keeps clients that stopped making requests from piling up.
+func (store *MemoryStore) prune(now time.Time) {
+	for key, b := range store.buckets {
+		if now.Sub(b.Updated) >= b.Per {
+			delete(store.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// How many times we try to update a bucket that other instances keep changing underneath us.
+const maxAttempts = 5
+
+// How long an idle bucket is kept before Mongo expires it. Any limit we'd configure refills well within this.
+const bucketExpiry = 24 * time.Hour
+
+// MongoStore keeps buckets in Mongo, so every instance behind a load balancer shares the same limits.
+type MongoStore struct{}
+
+// storedBucket is a bucket as it's saved in Mongo. Revision goes up by one with every update, so we can tell whether anyone else has changed it.
+type storedBucket struct {
+	Key      string    `bson:"_id"`
+	Tokens   float64   `bson:"tokens"`
+	Updated  time.Time `bson:"updated"`
+	Revision int       `bson:"revision"`
+}
+
+func storeBucket(key string, b bucket, revision int) storedBucket {
+	return storedBucket{Key: key, Tokens: b.Tokens, Updated: b.Updated, Revision: revision}
+}
+
+var registerOnce sync.Once
+
+// NewMongoStore shares rate limits through our Mongo server. Its index is only set up when it's used.
+func NewMongoStore() *MongoStore {
+	registerOnce.Do(func() {
+		common.RegisterMigration("ratelimit/expiration-index", ensureIndexes)
+	})
+	return &MongoStore{}
+}
+
+func collection(session *mgo.Session) *common.Collection {
+	return common.TimedCollection(common.Database(session, "ratelimit").C("buckets"))
+}
+
+// Lets Mongo clear out buckets for clients that stopped making requests.
+func ensureIndexes(session *mgo.Session) error {
+	return collection(session).EnsureIndex(mgo.Index{
+		Key:         []string{"updated"},
+		ExpireAfter: bucketExpiry,
+	})
+}
+
+// Take spends a token from the key's bucket, if there's one left. Each update only applies if the bucket's revision hasn't changed since we read it, so concurrent requests can't both spend the last token, even within the same millisecond.
+func (store *MongoStore) Take(key string, limit config.RateLimit, now time.Time) (bool, time.Duration, error) {
+	session, err := common.CopyMongoSession()
+	if err != nil {
+		return false, 0, err
+	}
+	defer session.Close()
+	c := collection(session)
+	// Mongo only keeps milliseconds; refill from the same time we'll read back.
+	now = now.Truncate(time.Millisecond)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		current := storedBucket{}
+		err := c.FindId(key).One(&current)
+		if err == mgo.ErrNotFound {
+			next, allowed, wait := fullBucket(limit, now).take(limit, now)
+			err = c.Insert(storeBucket(key, next, 1))
+			if mgo.IsDup(err) {
+				continue
+			} else if err != nil {
+				return false, 0, err
+			}
+			return allowed, wait, nil
+		} else if err != nil {
+			return false, 0, err
+		}
+
+		next, allowed, wait := bucket{Tokens: current.Tokens, Updated: current.Updated}.take(limit, now)
+		selector := bson.M{"_id": key, "revision": current.Revision}
+		if current.Revision == 0 {
+			// Buckets saved before we kept revisions don't have one.
+			selector["revision"] = bson.M{"$exists": false}
+		}
+		err = c.Update(selector, storeBucket(key, next, current.Revision+1))
+		if err == mgo.ErrNotFound {
+			continue
+		} else if err != nil {
+			return false, 0, err
+		}
+		return allowed, wait, nil
+	}
+	return false, 0, errContended
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Two requests in the same millisecond both read the bucket at its last token; only one of them may spend it.
+func TestMongoStoreConcurrentTakesAtTheSameTime(t *testing.T) {
+	if err := common.PingMongo(); err != nil {
+		t.Skipf("Mongo isn't available: %s", err.Error())
+	}
+	prefix := config.GetConfig().DatabasePrefix
+	defer func() { config.GetConfig().DatabasePrefix = prefix }()
+	config.GetConfig().DatabasePrefix = "test_"
+
+	session, err := common.CopyMongoSession()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer session.Close()
+	key := "test:" + time.Now().String()
+	defer collection(session).RemoveId(key)
+
+	store := NewMongoStore()
+	slow := config.RateLimit{Requests: 2, Per: time.Hour}
+	now := time.Now()
+	allowed, _, err := store.Take(key, slow, now)
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+
+	results := make(chan bool, 2)
+	var wg sync.WaitGroup
+	for idx := 0; idx < 2; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, _, err := store.Take(key, slow, now)
+			assert.Nil(t, err)
+			results <- allowed
+		}()
+	}
+	wg.Wait()
+	close(results)
+	spent := 0
+	for allowed := range results {
+		if allowed {
+			spent++
+		}
+	}
+	assert.Equal(t, 1, spent, "only one request should get the last token")
+}
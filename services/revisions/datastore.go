@@ -0,0 +1,30 @@
+package revisions
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	mgo "gopkg.in/mgo.v2"
+)
+
+type datastore struct {
+	session *mgo.Session
+}
+
+func init() {
+	common.RegisterMigration("revisions/history-index", ensureIndexes)
+}
+
+func newDatastore() *datastore {
+	return &datastore{common.GetMongoSession()}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "revision").C("revisions"))
+}
+
+// Lets us list a document's revisions in order without scanning every revision.
+func ensureIndexes(session *mgo.Session) error {
+	ds := datastore{session}
+	return ds.C().EnsureIndex(mgo.Index{
+		Key: []string{"tenant", "resource", "documentId", "-version"},
+	})
+}
@@ -0,0 +1,43 @@
+package revisions
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// List returns a document's revisions, newest first. Revisions outlive the document itself, but a document that never had any is reported as not found.
+func List(caller common.Caller, resource, id string) ([]models.Revision, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	results := make([]models.Revision, 0)
+	err := ds.C().Find(common.ForTenant(caller.TenantID, bson.M{
+		"resource":   resource,
+		"documentId": id,
+	})).Sort("-version").All(&results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, common.NotFoundErr
+	}
+	return results, nil
+}
+
+// Retrieve fetches the revision that produced this version of a document, showing the document as it was then.
+func Retrieve(caller common.Caller, resource, id string, version int) (*models.Revision, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	result := new(models.Revision)
+	err := ds.C().Find(common.ForTenant(caller.TenantID, bson.M{
+		"_id": revisionKey(resource, id, version),
+	})).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
+}
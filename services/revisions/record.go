@@ -0,0 +1,85 @@
+package revisions
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// These change with every save, so listing them as changes would only be noise.
+var ignoredPaths = map[string]bool{
+	"version":  true,
+	"modified": true,
+}
+
+// Record stores a revision for a document the caller just saved at this version, with a snapshot of current and how it differs from previous (nil if it was just created). The change was already saved, so if the revision can't be stored, we log it rather than report the change as failed.
+func Record(caller common.Caller, resource, id string, version int, action string, previous, current interface{}) {
+	revision, err := newRevision(resource, id, version, action, previous, current)
+	if err == nil {
+		revision.SetTenant(caller.TenantID)
+		ds := newDatastore()
+		defer ds.session.Close()
+		// Replace any revision left over from a version that was rolled back.
+		_, err = ds.C().Upsert(common.ForTenant(caller.TenantID, bson.M{
+			"_id": revision.Key,
+		}), revision)
+	}
+	if err != nil {
+		caller.Logger().Error("Couldn't record revision %d of %s %s: %s", version, resource, id, err.Error())
+	}
+}
+
+func newRevision(resource, id string, version int, action string, previous, current interface{}) (models.Revision, error) {
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	var previousSnapshot []byte
+	if previous != nil {
+		previousSnapshot, err = json.Marshal(previous)
+		if err != nil {
+			return models.Revision{}, err
+		}
+	}
+	changes, err := common.Diff(previousSnapshot, snapshot)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	relevant := make([]common.FieldChange, 0, len(changes))
+	for _, change := range changes {
+		if !ignoredPaths[change.Path] {
+			relevant = append(relevant, change)
+		}
+	}
+
+	return models.Revision{
+		Key:        revisionKey(resource, id, version),
+		Resource:   resource,
+		DocumentID: id,
+		Version:    version,
+		Action:     action,
+		Changes:    relevant,
+		Snapshot:   snapshot,
+		Created:    time.Now(),
+	}, nil
+}
+
+func revisionKey(resource, id string, version int) string {
+	return fmt.Sprintf("%s/%s/%d", resource, id, version)
+}
+
+// DiscardAfter forgets a document's revisions after the given version. It's meant for undoing changes, like rolling back a batch, so the history only shows what stuck. Use 0 to forget a document that was never really created.
+func DiscardAfter(caller common.Caller, resource, id string, version int) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	_, err := ds.C().RemoveAll(common.ForTenant(caller.TenantID, bson.M{
+		"resource":   resource,
+		"documentId": id,
+		"version":    bson.M{"$gt": version},
+	}))
+	return err
+}
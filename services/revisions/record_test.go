@@ -0,0 +1,27 @@
+package revisions
+
+import (
+	"testing"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRevision(t *testing.T) {
+	type doc struct {
+		Name     string `json:"name"`
+		Version  int    `json:"version"`
+		Modified string `json:"modified"`
+	}
+
+	created, err := newRevision("plans", "a", 1, models.RevisionCreate, nil, doc{Name: "x", Version: 1, Modified: "then"})
+	assert.Nil(t, err)
+	assert.Equal(t, "plans/a/1", created.Key)
+	assert.Equal(t, []common.FieldChange{{Path: "name", Old: nil, New: "x"}}, created.Changes)
+	assert.JSONEq(t, `{"name":"x","version":1,"modified":"then"}`, string(created.Snapshot))
+
+	updated, err := newRevision("plans", "a", 2, models.RevisionUpdate, doc{Name: "x", Version: 1, Modified: "then"}, doc{Name: "y", Version: 2, Modified: "now"})
+	assert.Nil(t, err)
+	assert.Equal(t, []common.FieldChange{{Path: "name", Old: "x", New: "y"}}, updated.Changes, "version and modified shouldn't be listed as changes")
+}
@@ -0,0 +1,33 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Authenticate returns the AccessToken for this token, as long as it hasn't expired or been revoked, and notes that it was just used.
+func Authenticate(logger common.Logger, secret string) (*models.AccessToken, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	token, err := ds.findHash(hashSecret(secret))
+	if err == common.NotFoundErr {
+		return nil, common.UnauthorizedErr
+	} else if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, common.UnauthorizedErr
+	}
+
+	// The request can go ahead even if we can't record when the token was used.
+	err = ds.C().UpdateId(token.ID, bson.M{"$set": bson.M{"lastUsed": now}})
+	if err != nil {
+		logger.Warn("Couldn't record when access token %s was used: %s", token.ID, err.Error())
+	}
+	token.LastUsed = &now
+	return token, nil
+}
@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// Create issues the caller a new AccessToken with the given name, scopes, and expiry. The token itself is only ever returned here.
+func Create(caller common.Caller, input models.AccessToken) (*models.NewAccessToken, error) {
+	input, err := input.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	input.ID = models.NewSafeUUID()
+	input.UserID = models.SafeUUID(caller.UserID)
+	input.Hash = hashSecret(secret)
+	input.LastUsed = nil
+	input.SetCreationTimestamp()
+
+	ds := newDatastore()
+	defer ds.session.Close()
+	err = ds.C().Insert(input)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NewAccessToken{AccessToken: input, Token: secret}, nil
+}
@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type datastore struct {
+	session *mgo.Session
+}
+
+func init() {
+	common.RegisterMigration("tokens/lookup-indexes", ensureIndexes)
+}
+
+func newDatastore() *datastore {
+	return &datastore{common.GetMongoSession()}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "token").C("tokens"))
+}
+
+// Lets us look tokens up by their hash, and list a user's tokens, without scanning them all.
+func ensureIndexes(session *mgo.Session) error {
+	ds := datastore{session}
+	for _, index := range []mgo.Index{
+		{Key: []string{"hash"}, Unique: true},
+		{Key: []string{"userId"}},
+	} {
+		err := ds.C().EnsureIndex(index)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findHash fetches a single AccessToken by the hash of the token, translating a missing document into our NotFoundErr.
+func (ds datastore) findHash(hash string) (*models.AccessToken, error) {
+	result := new(models.AccessToken)
+	err := ds.C().Find(bson.M{"hash": hash}).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
+}
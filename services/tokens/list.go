@@ -0,0 +1,31 @@
+package tokens
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// List returns the caller's AccessTokens, newest first, including expired ones.
+func List(caller common.Caller) ([]models.AccessToken, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	results := make([]models.AccessToken, 0)
+	err := ds.C().Find(bson.M{"userId": caller.UserID}).Sort("-timestamped.created").All(&results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Revoke permanently removes one of the caller's AccessTokens, so it can't be used anymore.
+func Revoke(caller common.Caller, id string) error {
+	ds := newDatastore()
+	defer ds.session.Close()
+	err := ds.C().Remove(bson.M{"_id": id, "userId": caller.UserID})
+	if err == mgo.ErrNotFound {
+		return common.NotFoundErr
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/hjkelly/zbbapi/models"
+)
+
+// Makes up a new access token: the prefix, then 32 random bytes.
+func newSecret() (string, error) {
+	random := make([]byte, 32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+	return models.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// Tokens are long and random, so a fast hash is enough to keep a leaked database from revealing them.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
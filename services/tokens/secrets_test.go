@@ -0,0 +1,24 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecret(t *testing.T) {
+	first, err := newSecret()
+	assert.Nil(t, err)
+	second, err := newSecret()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(first, models.AccessTokenPrefix))
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashSecret(t *testing.T) {
+	assert.Equal(t, hashSecret("zbb_pat_abc"), hashSecret("zbb_pat_abc"))
+	assert.NotEqual(t, hashSecret("zbb_pat_abc"), hashSecret("zbb_pat_abd"))
+	assert.NotContains(t, hashSecret("zbb_pat_abc"), "abc", "the hash shouldn't reveal the token")
+}
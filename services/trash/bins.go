@@ -0,0 +1,116 @@
+package trash
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/budgets"
+	"github.com/hjkelly/zbbapi/services/categories"
+	"github.com/hjkelly/zbbapi/services/plans"
+)
+
+// bin adapts one service package's trash to what we need here.
+type bin struct {
+	list        func(caller common.Caller) ([]models.TrashItem, error)
+	restore     func(caller common.Caller, id string) (interface{}, error)
+	purgeBefore func(cutoff time.Time) ([]purgedDoc, error)
+}
+
+// purgedDoc identifies a document the sweeper removed for good.
+type purgedDoc struct {
+	TenantID string
+	ID       string
+}
+
+var bins = map[string]bin{
+	"plans":      {list: listPlans, restore: restorePlan, purgeBefore: purgePlans},
+	"budgets":    {list: listBudgets, restore: restoreBudget, purgeBefore: purgeBudgets},
+	"categories": {list: listCategories, restore: restoreCategory, purgeBefore: purgeCategories},
+}
+
+// Describes a trashed document. Every document in the trash has a deletion time.
+func trashItem(resource, id string, deleted *time.Time, doc interface{}) models.TrashItem {
+	return models.TrashItem{Resource: resource, ID: id, Deleted: *deleted, Document: doc}
+}
+
+// CATEGORIES ----------
+
+func listCategories(caller common.Caller) ([]models.TrashItem, error) {
+	results, err := categories.ListTrash(caller)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.TrashItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, trashItem("categories", result.ID.String(), result.Deleted, result))
+	}
+	return items, nil
+}
+
+func restoreCategory(caller common.Caller, id string) (interface{}, error) {
+	return categories.Restore(caller, id)
+}
+
+func purgeCategories(cutoff time.Time) ([]purgedDoc, error) {
+	results, err := categories.PurgeDeletedBefore(cutoff)
+	purged := make([]purgedDoc, 0, len(results))
+	for _, result := range results {
+		purged = append(purged, purgedDoc{result.Tenant, result.ID.String()})
+	}
+	return purged, err
+}
+
+// PLANS ----------
+
+func listPlans(caller common.Caller) ([]models.TrashItem, error) {
+	results, err := plans.ListTrash(caller)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.TrashItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, trashItem("plans", string(result.ID), result.Deleted, result))
+	}
+	return items, nil
+}
+
+func restorePlan(caller common.Caller, id string) (interface{}, error) {
+	return plans.Restore(caller, id)
+}
+
+func purgePlans(cutoff time.Time) ([]purgedDoc, error) {
+	results, err := plans.PurgeDeletedBefore(cutoff)
+	purged := make([]purgedDoc, 0, len(results))
+	for _, result := range results {
+		purged = append(purged, purgedDoc{result.Tenant, string(result.ID)})
+	}
+	return purged, err
+}
+
+// BUDGETS ----------
+
+func listBudgets(caller common.Caller) ([]models.TrashItem, error) {
+	results, err := budgets.ListTrash(caller)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.TrashItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, trashItem("budgets", string(result.ID), result.Deleted, result))
+	}
+	return items, nil
+}
+
+func restoreBudget(caller common.Caller, id string) (interface{}, error) {
+	return budgets.Restore(caller, id)
+}
+
+func purgeBudgets(cutoff time.Time) ([]purgedDoc, error) {
+	results, err := budgets.PurgeDeletedBefore(cutoff)
+	purged := make([]purgedDoc, 0, len(results))
+	for _, result := range results {
+		purged = append(purged, purgedDoc{result.Tenant, string(result.ID)})
+	}
+	return purged, err
+}
@@ -0,0 +1,30 @@
+package trash
+
+import (
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+)
+
+// StartSweeper purges anything that's been in the trash longer than the retention period, checking once every interval. It runs in the background for as long as the program does.
+func StartSweeper(retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			sweepOnce(retention)
+			<-ticker.C
+		}
+	}()
+}
+
+func sweepOnce(retention time.Duration) {
+	purged, err := Sweep(time.Now().Add(-retention))
+	if err != nil {
+		common.Log.Error("Couldn't empty the trash: %s", err.Error())
+		return
+	}
+	if purged > 0 {
+		common.Log.Info("Purged %d resources from the trash.", purged)
+	}
+}
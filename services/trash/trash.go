@@ -0,0 +1,58 @@
+package trash
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/hjkelly/zbbapi/services/revisions"
+)
+
+// List returns everything in the caller's trash, most recently deleted first.
+func List(caller common.Caller) ([]models.TrashItem, error) {
+	all := make([]models.TrashItem, 0)
+	for _, resource := range models.TrashResources {
+		items, err := bins[resource].list(caller)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Deleted.After(all[j].Deleted)
+	})
+	return all, nil
+}
+
+// Restore takes a resource back out of the trash, and returns it as it is now.
+func Restore(caller common.Caller, resource, id string) (interface{}, error) {
+	b, ok := bins[resource]
+	if !ok {
+		return nil, common.NotFoundErr
+	}
+	return b.restore(caller, id)
+}
+
+// discardRevisions forgets a purged document's history; tests swap it out.
+var discardRevisions = revisions.DiscardAfter
+
+// Sweep permanently removes everything that was moved to the trash before the cutoff, along with its revisions, and says how much there was.
+func Sweep(cutoff time.Time) (int, error) {
+	total := 0
+	for _, resource := range models.TrashResources {
+		purged, err := bins[resource].purgeBefore(cutoff)
+		for _, doc := range purged {
+			// The document is gone either way, so forget the rest's history before reporting a problem.
+			discardErr := discardRevisions(common.Caller{TenantID: doc.TenantID}, resource, doc.ID, 0)
+			if discardErr != nil && err == nil {
+				err = discardErr
+			}
+		}
+		total += len(purged)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
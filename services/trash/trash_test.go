@@ -0,0 +1,131 @@
+package trash
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// The tenant whose items the fake bins hold.
+var owner = common.Caller{UserID: "u1", TenantID: "t1"}
+
+// Makes bins that don't need a database: each holds the given items for the owner's tenant, and purging reports the ones deleted before the cutoff.
+func fakeBins(items map[string][]models.TrashItem) map[string]bin {
+	fakes := map[string]bin{}
+	for _, resource := range models.TrashResources {
+		resourceItems := items[resource]
+		fakes[resource] = bin{
+			list: func(caller common.Caller) ([]models.TrashItem, error) {
+				if caller.TenantID != owner.TenantID {
+					return nil, nil
+				}
+				return resourceItems, nil
+			},
+			restore: func(caller common.Caller, id string) (interface{}, error) {
+				for _, item := range resourceItems {
+					if item.ID == id && caller.TenantID == owner.TenantID {
+						return item.Document, nil
+					}
+				}
+				return nil, common.NotFoundErr
+			},
+			purgeBefore: func(cutoff time.Time) ([]purgedDoc, error) {
+				purged := []purgedDoc{}
+				for _, item := range resourceItems {
+					if item.Deleted.Before(cutoff) {
+						purged = append(purged, purgedDoc{owner.TenantID, item.ID})
+					}
+				}
+				return purged, nil
+			},
+		}
+	}
+	return fakes
+}
+
+func TestList(t *testing.T) {
+	now := time.Now()
+	original := bins
+	defer func() { bins = original }()
+	bins = fakeBins(map[string][]models.TrashItem{
+		"categories": {{Resource: "categories", ID: "old", Deleted: now.Add(-time.Hour)}},
+		"plans":      {{Resource: "plans", ID: "new", Deleted: now}},
+	})
+	items, err := List(owner)
+	assert.Nil(t, err)
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"new", "old"}, ids, "the most recently deleted should come first")
+}
+
+func TestRestore(t *testing.T) {
+	original := bins
+	defer func() { bins = original }()
+	bins = fakeBins(map[string][]models.TrashItem{
+		"plans": {{Resource: "plans", ID: "a", Document: "plan a"}},
+	})
+	result, err := Restore(owner, "plans", "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "plan a", result)
+
+	_, err = Restore(owner, "plans", "b")
+	assert.Equal(t, common.NotFoundErr, err)
+	_, err = Restore(owner, "spaceships", "a")
+	assert.Equal(t, common.NotFoundErr, err)
+}
+
+func TestOtherTenantsTrash(t *testing.T) {
+	original := bins
+	defer func() { bins = original }()
+	bins = fakeBins(map[string][]models.TrashItem{
+		"plans": {{Resource: "plans", ID: "a", Document: "plan a"}},
+	})
+	intruder := common.Caller{UserID: "u2", TenantID: "t2"}
+	items, err := List(intruder)
+	assert.Nil(t, err)
+	assert.Empty(t, items, "another tenant's trash shouldn't be listed")
+	_, err = Restore(intruder, "plans", "a")
+	assert.Equal(t, common.NotFoundErr, err, "another tenant's trash shouldn't be restorable")
+}
+
+// Records which documents' revisions were discarded, instead of touching the database.
+func fakeDiscard(discarded *[]string) func(common.Caller, string, string, int) error {
+	return func(caller common.Caller, resource, id string, version int) error {
+		*discarded = append(*discarded, caller.TenantID+"/"+resource+"/"+id)
+		return nil
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	original, originalDiscard := bins, discardRevisions
+	defer func() { bins, discardRevisions = original, originalDiscard }()
+	bins = fakeBins(map[string][]models.TrashItem{
+		"categories": {{ID: "old", Deleted: now.Add(-48 * time.Hour)}, {ID: "new", Deleted: now}},
+		"budgets":    {{ID: "older", Deleted: now.Add(-72 * time.Hour)}},
+	})
+	discarded := []string{}
+	discardRevisions = fakeDiscard(&discarded)
+
+	purged, err := Sweep(now.Add(-24 * time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, []string{"t1/categories/old", "t1/budgets/older"}, discarded, "purged documents' revisions should go with them")
+}
+
+func TestSweepError(t *testing.T) {
+	original := bins
+	defer func() { bins = original }()
+	bins = fakeBins(nil)
+	bins["budgets"] = bin{purgeBefore: func(time.Time) ([]purgedDoc, error) {
+		return nil, errors.New("nope")
+	}}
+	_, err := Sweep(time.Now())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,55 @@
+package users
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type datastore struct {
+	session *mgo.Session
+}
+
+func init() {
+	common.RegisterMigration("users/email-index", ensureIndexes)
+}
+
+func newDatastore() *datastore {
+	return &datastore{common.GetMongoSession()}
+}
+
+func (ds datastore) C() *common.Collection {
+	return common.TimedCollection(common.Database(ds.session, "user").C("users"))
+}
+
+// Makes sure two users can't share an email address, even if they register at the same time.
+func ensureIndexes(session *mgo.Session) error {
+	ds := datastore{session}
+	return ds.C().EnsureIndex(mgo.Index{
+		Key:    []string{"email"},
+		Unique: true,
+	})
+}
+
+// findID fetches a single User by ID, translating a missing document into our NotFoundErr.
+func (ds datastore) findID(id string) (*models.User, error) {
+	return ds.findOne(bson.M{"_id": id})
+}
+
+// findEmail fetches a single User by their (normalized) email address, translating a missing document into our NotFoundErr.
+func (ds datastore) findEmail(email string) (*models.User, error) {
+	return ds.findOne(bson.M{"email": email})
+}
+
+func (ds datastore) findOne(selector bson.M) (*models.User, error) {
+	result := new(models.User)
+	err := ds.C().Find(selector).One(result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, common.NotFoundErr
+		}
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package users
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+	"github.com/hjkelly/zbbapi/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var decoyOnce sync.Once
+var decoyHash []byte
+
+// When there's no such user, we still check the password against something, so the response doesn't come back noticeably faster and reveal who's registered.
+func decoy() []byte {
+	decoyOnce.Do(func() {
+		decoyHash, _ = bcrypt.GenerateFromPassword([]byte("not anyone's password"), bcrypt.DefaultCost)
+	})
+	return decoyHash
+}
+
+// Login checks the credentials and, if they match a User, issues them a token.
+func Login(input models.Credentials) (*models.Session, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	user, err := ds.findEmail(models.NormalizeEmail(input.Email))
+	if err == common.NotFoundErr {
+		bcrypt.CompareHashAndPassword(decoy(), []byte(input.Password))
+		return nil, common.BadCredentialsErr
+	} else if err != nil {
+		return nil, err
+	}
+	err = bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(input.Password))
+	if err != nil {
+		return nil, common.BadCredentialsErr
+	}
+
+	token, expires := issueToken(string(user.ID), time.Now(), config.GetConfig().TokenLifetime, signingKey())
+	return &models.Session{
+		Token:   token,
+		Expires: expires,
+		User:    *user,
+	}, nil
+}
+
+// Authenticate returns the User a token was issued to, as long as it's still valid.
+func Authenticate(token string) (*models.User, error) {
+	userID, err := TokenUserID(token)
+	if err != nil {
+		return nil, err
+	}
+	ds := newDatastore()
+	defer ds.session.Close()
+	user, err := ds.findID(userID)
+	if err == common.NotFoundErr {
+		return nil, common.UnauthorizedErr
+	}
+	return user, err
+}
+
+// TokenUserID returns the ID of the user a token was issued to, as long as we signed it and it hasn't expired. Unlike Authenticate, it doesn't check that the user still exists, so it doesn't need the database.
+func TokenUserID(token string) (string, error) {
+	return parseToken(token, time.Now(), signingKey())
+}
+
+// Retrieve fetches a single User, if their ID exists.
+func Retrieve(id string) (*models.User, error) {
+	ds := newDatastore()
+	defer ds.session.Close()
+	return ds.findID(id)
+}
@@ -0,0 +1,22 @@
+package users
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/metrics"
+)
+
+func init() {
+	metrics.NewGaugeFunc("zbbapi_users", "How many users have registered.", count)
+}
+
+// Counts every User.
+func count() (float64, error) {
+	session, err := common.CopyMongoSession()
+	if err != nil {
+		return 0, err
+	}
+	ds := datastore{session}
+	defer ds.session.Close()
+	n, err := ds.C().Count()
+	return float64(n), err
+}
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/models"
+	"golang.org/x/crypto/bcrypt"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// Register creates a User who can log in with these credentials. Each email address can only be registered once.
+func Register(input models.Credentials) (*models.User, error) {
+	creds, err := input.GetValidated()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(creds.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		ID:           models.NewSafeUUID(),
+		Email:        creds.Email,
+		PasswordHash: hash,
+	}
+	user.SetCreationTimestamp()
+	user.SetInitialVersion()
+
+	ds := newDatastore()
+	defer ds.session.Close()
+	err = ds.C().Insert(user)
+	if err != nil {
+		if mgo.IsDup(err) {
+			return nil, common.NewValidationError("email", common.DuplicateCode, "registered")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
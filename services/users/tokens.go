@@ -0,0 +1,83 @@
+package users
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/hjkelly/zbbapi/config"
+)
+
+// Tokens are JSON Web Tokens signed with HMAC-SHA256, so we can check them without storing them anywhere.
+type tokenClaims struct {
+	Subject  string `json:"sub"`
+	IssuedAt int64  `json:"iat"`
+	Expires  int64  `json:"exp"`
+}
+
+var tokenEncoding = base64.RawURLEncoding
+var tokenHeader = tokenEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+var keyOnce sync.Once
+var key []byte
+
+// signingKey comes from the configuration. Without one, we make up a random key, which works, but means everyone has to log in again after a restart.
+func signingKey() []byte {
+	keyOnce.Do(func() {
+		key = []byte(config.GetConfig().AuthSecret)
+		if len(key) == 0 {
+			common.Log.Warn("No AUTH_SECRET is configured, so tokens will only be valid until the server restarts.")
+			key = make([]byte, 32)
+			_, err := rand.Read(key)
+			if err != nil {
+				panic(err)
+			}
+		}
+	})
+	return key
+}
+
+// issueToken creates a token identifying the user until it expires.
+func issueToken(userID string, now time.Time, lifetime time.Duration, secret []byte) (string, time.Time) {
+	expires := now.Add(lifetime)
+	claims, _ := json.Marshal(tokenClaims{
+		Subject:  userID,
+		IssuedAt: now.Unix(),
+		Expires:  expires.Unix(),
+	})
+	unsigned := tokenHeader + "." + tokenEncoding.EncodeToString(claims)
+	return unsigned + "." + sign(unsigned, secret), expires
+}
+
+// parseToken returns the ID of the user the token identifies, as long as we signed it and it hasn't expired.
+func parseToken(token string, now time.Time, secret []byte) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return "", common.UnauthorizedErr
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(parts[0]+"."+parts[1], secret))) {
+		return "", common.UnauthorizedErr
+	}
+	claimsJSON, err := tokenEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", common.UnauthorizedErr
+	}
+	claims := tokenClaims{}
+	err = json.Unmarshal(claimsJSON, &claims)
+	if err != nil || len(claims.Subject) == 0 || now.Unix() >= claims.Expires {
+		return "", common.UnauthorizedErr
+	}
+	return claims.Subject, nil
+}
+
+func sign(unsigned string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return tokenEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,49 @@
+package users
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjkelly/zbbapi/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	token, expires := issueToken("user-1", now, time.Hour, secret)
+	assert.Equal(t, now.Add(time.Hour), expires)
+
+	userID, err := parseToken(token, now.Add(time.Minute), secret)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", userID)
+
+	parts := strings.Split(token, ".")
+	otherToken, _ := issueToken("user-2", now, time.Hour, secret)
+	for _, testCase := range []struct {
+		desc  string
+		token string
+		now   time.Time
+		key   []byte
+	}{
+		{"expired", token, now.Add(time.Hour), secret},
+		{"wrong key", token, now, []byte("other")},
+		{"tampered claims", parts[0] + "." + strings.Split(otherToken, ".")[1] + "." + parts[2], now, secret},
+		{"not a token", "nope", now, secret},
+		{"empty", "", now, secret},
+	} {
+		_, err := parseToken(testCase.token, testCase.now, testCase.key)
+		assert.Equal(t, common.UnauthorizedErr, err, "CASE: %s", testCase.desc)
+	}
+}
+
+func TestTokenUserID(t *testing.T) {
+	token, _ := issueToken("user-1", time.Now(), time.Hour, signingKey())
+	userID, err := TokenUserID(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", userID)
+
+	_, err = TokenUserID("made-up")
+	assert.Equal(t, common.UnauthorizedErr, err)
+}